@@ -0,0 +1,85 @@
+package yagclif
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes parsed from
+// a human readable size such as 512K, 10MB or 1.5GiB.
+// Decimal units (K, KB, M, MB ...) are powers of 1000
+// and binary units (Ki, KiB, Mi, MiB ...) are powers of 1024.
+type ByteSize int64
+
+// Units of a ByteSize by suffix, suffixes are case insensitive.
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// Units used to format a ByteSize from the largest to the smallest.
+var byteSizeFormats = []struct {
+	suffix string
+	size   int64
+}{
+	{"TiB", 1 << 40}, {"TB", 1000 * 1000 * 1000 * 1000},
+	{"GiB", 1 << 30}, {"GB", 1000 * 1000 * 1000},
+	{"MiB", 1 << 20}, {"MB", 1000 * 1000},
+	{"KiB", 1 << 10}, {"KB", 1000},
+}
+
+// Set parses a human readable size.
+func (b *ByteSize) Set(s string) error {
+	value := strings.TrimSpace(s)
+	numberEnd := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if numberEnd == -1 {
+		numberEnd = len(value)
+	}
+	number, suffix := value[:numberEnd], strings.TrimSpace(value[numberEnd:])
+	unit, found := byteSizeUnits[strings.ToLower(suffix)]
+	if !found {
+		return fmt.Errorf("unknown size unit %s", suffix)
+	}
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %s", s)
+	}
+	total := size * float64(unit)
+	// float64(math.MaxInt64) is 2^63, out of range.
+	if total >= math.MaxInt64 {
+		return fmt.Errorf("size %s is out of range", s)
+	}
+	*b = ByteSize(total)
+	return nil
+}
+
+// String formats the size with the largest unit dividing it.
+func (b *ByteSize) String() string {
+	size := int64(*b)
+	for _, format := range byteSizeFormats {
+		if size != 0 && size%format.size == 0 {
+			return fmt.Sprint(size/format.size, format.suffix)
+		}
+	}
+	return fmt.Sprint(size, "B")
+}
@@ -0,0 +1,47 @@
+package yagclif
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteSizeSet(t *testing.T) {
+	t.Run("works", func(t *testing.T) {
+		expectedSizes := map[string]ByteSize{
+			"42":     42,
+			"42B":    42,
+			"512K":   512 * 1000,
+			"10MB":   10 * 1000 * 1000,
+			"1.5GiB": 3 << 29,
+			"2 kib":  2048,
+		}
+		for s, expectedSize := range expectedSizes {
+			var size ByteSize
+			assert.Nil(t, size.Set(s), s)
+			assert.Equal(t, expectedSize, size, s)
+		}
+	})
+	t.Run("returns error", func(t *testing.T) {
+		var size ByteSize
+		assert.NotNil(t, size.Set("10XB"))
+		assert.NotNil(t, size.Set("MB"))
+		assert.NotNil(t, size.Set("1.2.3K"))
+		assert.NotNil(t, size.Set("99999999999TiB"))
+		assert.NotNil(t, size.Set("8388608TiB"))
+		assert.Nil(t, size.Set("8388607TiB"))
+	})
+}
+
+func TestByteSizeString(t *testing.T) {
+	expectedStrings := map[ByteSize]string{
+		0:                "0B",
+		42:               "42B",
+		2048:             "2KiB",
+		10 * 1000 * 1000: "10MB",
+		3 << 29:          "1536MiB",
+	}
+	for size, expectedString := range expectedStrings {
+		assert.Equal(t, expectedString, size.String())
+	}
+}
@@ -0,0 +1,132 @@
+package yagclif
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Returns the command parameters in the
+// order of the struct fields.
+func (params *parameters) commands() parameters {
+	commands := parameters{}
+	for _, param := range *params {
+		if param.command {
+			commands = append(commands, param)
+		}
+	}
+	return commands
+}
+
+// Finds a command parameter by its name or aliases.
+func (params *parameters) findCommand(s string) *parameter {
+	for _, param := range *params {
+		if !param.command {
+			continue
+		}
+		for _, name := range param.commandNames() {
+			if param.matchesName(name, s) {
+				return param
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the names of the used commands from
+// the top level one to the leaf one.
+func (params *parameters) selectedCommands() []string {
+	for _, command := range params.commands() {
+		if command.used {
+			return append(
+				[]string{command.commandNames()[0]},
+				command.commandParams.selectedCommands()...,
+			)
+		}
+	}
+	return []string{}
+}
+
+// Returns the names selecting a command,
+// they are not prefixed by the parents names.
+func (p *parameter) commandNames() []string {
+	name := p.options().naming(p.name)
+	if p.cliName != "" {
+		name = p.cliName
+	}
+	names := []string{p.cased(name)}
+	for _, alias := range p.aliases {
+		names = append(names, p.cased(alias))
+	}
+	return names
+}
+
+// Fills the struct of the command with its arguments
+// and returns the remaining ones, nil struct
+// pointers are allocated.
+func (p *parameter) runCommand(obj interface{}, args []string, settings parseSettings) ([]string, error) {
+	if p.deprecated {
+		p.warnDeprecated()
+	}
+	p.used = true
+	target := p.getValue(obj)
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(p.valueType()))
+		}
+	} else {
+		target = target.Addr()
+	}
+	if p.interspersed != nil {
+		settings.interspersed = *p.interspersed
+	}
+	return p.commandParams.parseArguments(target.Interface(), args, settings)
+}
+
+// Returns the help of a command without its parameters.
+func (p *parameter) commandHelp() string {
+	help := strings.Join(p.CliNames(), " ")
+	if p.mandatory {
+		help += " (mandatory)"
+	}
+	if p.description != "" {
+		help += " : " + p.description
+	}
+	return help
+}
+
+// Returns the help of the visible commands
+// followed by the help of their parameters.
+func (params *parameters) commandsHelp() []string {
+	var buffer []string
+	for _, command := range params.commands() {
+		if command.hidden {
+			continue
+		}
+		buffer = append(buffer, groupIndent+command.GetHelp())
+		for _, line := range command.commandParams.getHelp() {
+			buffer = append(buffer, groupIndent+groupIndent+line)
+		}
+	}
+	if len(buffer) == 0 {
+		return nil
+	}
+	return append([]string{"Commands:"}, buffer...)
+}
+
+// Validates the constraints of a command parameter.
+func (p *parameter) validateCommand() error {
+	getError := func(s string) error {
+		return fmt.Errorf("parameter %s : %s",
+			p.name, s,
+		)
+	}
+	if p.valueType().Kind() != reflect.Struct {
+		return getError("command on non struct type")
+	} else if p.positional || p.shortName != "" {
+		return getError("command can not be positional or have a shortname")
+	} else if p.defaultValue != "" || p.defaultFunc != "" || p.env != "" {
+		return getError("command can not have a default value or env")
+	}
+	return nil
+}
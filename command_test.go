@@ -0,0 +1,179 @@
+package yagclif
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type serveCommand struct {
+	Port    int `yagclif:"default:80"`
+	Verbose bool
+}
+
+type migrateCommand struct {
+	DryRun bool
+}
+
+type commandsTool struct {
+	Config  string
+	Serve   serveCommand    `yagclif:"command;description:starts the server"`
+	Migrate *migrateCommand `yagclif:"command;aliases:mig"`
+}
+
+type setCommand struct {
+	Key   string `yagclif:"positional;mandatory"`
+	Value string `yagclif:"positional;mandatory"`
+}
+
+type configCommand struct {
+	Global bool
+	Set    setCommand `yagclif:"command"`
+	Get    struct {
+		Key string `yagclif:"positional"`
+	} `yagclif:"command"`
+}
+
+type commandsTree struct {
+	Verbose bool
+	Config  configCommand `yagclif:"command;aliases:cfg"`
+	Version struct{}      `yagclif:"command"`
+}
+
+func TestCommands(t *testing.T) {
+	t.Run("selects commands", func(t *testing.T) {
+		params, err := newParameters(reflect.TypeOf(commandsTool{}))
+		assert.Nil(t, err)
+		tool := &commandsTool{}
+		remaining, err := params.ParseArguments(tool, []string{"--config", "a", "serve", "--port", "8080", "x"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"x"}, remaining)
+		assert.Equal(t, &commandsTool{Config: "a", Serve: serveCommand{Port: 8080}}, tool)
+		params, err = newParameters(reflect.TypeOf(commandsTool{}))
+		assert.Nil(t, err)
+		tool = &commandsTool{}
+		remaining, err = params.ParseArguments(tool, []string{"mig", "--dryrun", "--config", "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--config", "b"}, remaining)
+		assert.Equal(t, &commandsTool{Migrate: &migrateCommand{DryRun: true}}, tool)
+	})
+	t.Run("command errors", func(t *testing.T) {
+		params, err := newParameters(reflect.TypeOf(commandsTool{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&commandsTool{}, []string{"serve", "--port", "http"})
+		assert.NotNil(t, err)
+		type mandatory struct {
+			Serve serveCommand `yagclif:"command;mandatory"`
+		}
+		params, err = newParameters(reflect.TypeOf(mandatory{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&mandatory{}, []string{})
+		assert.NotNil(t, err)
+	})
+	t.Run("invalid commands", func(t *testing.T) {
+		type notStruct struct {
+			Serve string `yagclif:"command"`
+		}
+		type shortName struct {
+			Serve serveCommand `yagclif:"command;shortname:s"`
+		}
+		type invalidParams struct {
+			Serve struct {
+				Port int `yagclif:"default:http"`
+			} `yagclif:"command"`
+		}
+		for _, tipe := range []reflect.Type{
+			reflect.TypeOf(notStruct{}),
+			reflect.TypeOf(shortName{}),
+			reflect.TypeOf(invalidParams{}),
+		} {
+			params, err := newParameters(tipe)
+			assert.NotNil(t, err)
+			assert.Nil(t, params)
+		}
+	})
+	t.Run("command trees", func(t *testing.T) {
+		params, err := newParameters(reflect.TypeOf(commandsTree{}))
+		assert.Nil(t, err)
+		tree := &commandsTree{}
+		session, remaining, err := params.parseSession(tree, []string{"--verbose", "cfg", "--global", "set", "key", "value"}, newParseSettings(context.Background(), false))
+		assert.Nil(t, err)
+		assert.Empty(t, remaining)
+		assert.Equal(t, []string{"config", "set"}, session.selectedCommands())
+		assert.True(t, tree.Verbose)
+		assert.Equal(t, configCommand{Global: true, Set: setCommand{Key: "key", Value: "value"}}, tree.Config)
+		params, err = newParameters(reflect.TypeOf(commandsTree{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&commandsTree{}, []string{"config", "set", "key"})
+		assert.NotNil(t, err)
+		params, err = newParameters(reflect.TypeOf(commandsTree{}))
+		assert.Nil(t, err)
+		session, _, err = params.parseSession(&commandsTree{}, []string{"version"}, newParseSettings(context.Background(), false))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"version"}, session.selectedCommands())
+		params, err = newParameters(reflect.TypeOf(commandsTree{}))
+		assert.Nil(t, err)
+		assert.Empty(t, params.selectedCommands())
+	})
+	t.Run("parse command", func(t *testing.T) {
+		os.Args = []string{"./main", "config", "get", "key"}
+		tree := &commandsTree{}
+		commands, remaining, err := ParseCommand(tree)
+		assert.Nil(t, err)
+		assert.Empty(t, remaining)
+		assert.Equal(t, []string{"config", "get"}, commands)
+		assert.Equal(t, "key", tree.Config.Get.Key)
+		os.Args = []string{"./main", "config", "set"}
+		_, _, err = ParseCommand(&commandsTree{})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "usage")
+	})
+	t.Run("interspersed commands", func(t *testing.T) {
+		type run struct {
+			Detach bool
+			Image  string `yagclif:"positional;mandatory"`
+		}
+		type tool struct {
+			Verbose bool
+			Run     run `yagclif:"command;interspersed:false"`
+			Build   run `yagclif:"command"`
+		}
+		params, err := newParameters(reflect.TypeOf(tool{}))
+		assert.Nil(t, err)
+		testStruct := &tool{}
+		remaining, err := params.ParseArguments(testStruct, []string{"run", "--detach", "alpine", "ls", "--detach"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"ls", "--detach"}, remaining)
+		assert.Equal(t, run{Detach: true, Image: "alpine"}, testStruct.Run)
+		params, err = newParameters(reflect.TypeOf(tool{}))
+		assert.Nil(t, err)
+		testStruct = &tool{}
+		remaining, err = params.ParseArguments(testStruct, []string{"build", "alpine", "ls", "--detach"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"ls"}, remaining)
+		assert.Equal(t, run{Detach: true, Image: "alpine"}, testStruct.Build)
+		type invalid struct {
+			Verbose bool `yagclif:"interspersed:false"`
+			Run     run  `yagclif:"command;interspersed:no"`
+		}
+		for i := 0; i < 2; i++ {
+			param, err := newParameter(reflect.TypeOf(invalid{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("help", func(t *testing.T) {
+		params, err := newParameters(reflect.TypeOf(commandsTool{}))
+		assert.Nil(t, err)
+		help := params.getHelp()
+		assert.Equal(t, "Commands:", help[1])
+		assert.Equal(t, groupIndent+"serve : starts the server", help[2])
+		assert.True(t, strings.HasPrefix(help[3], groupIndent+groupIndent+"--port"))
+		assert.Equal(t, groupIndent+"migrate mig", help[5])
+		assert.Equal(t, "mytool [flags] [COMMAND]", params.usageLine("mytool"))
+	})
+}
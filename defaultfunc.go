@@ -0,0 +1,58 @@
+package yagclif
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultFunc computes the default value of a parameter when parsing.
+type DefaultFunc func() (string, error)
+
+// ContextDefaultFunc is a DefaultFunc getting
+// the context of the parsing.
+type ContextDefaultFunc func(ctx context.Context) (string, error)
+
+// Default functions usable with the defaultfn constraint by name.
+var defaultFuncs = map[string]ContextDefaultFunc{}
+
+// RegisterDefaultFunc registers a function computing default
+// values usable by the parameters with the defaultfn constraint,
+// functions must be registered before parsing.
+func RegisterDefaultFunc(name string, defaultFunc DefaultFunc) error {
+	if defaultFunc == nil {
+		return fmt.Errorf("default function %s is nil", name)
+	}
+	return RegisterContextDefaultFunc(name, func(ctx context.Context) (string, error) {
+		return defaultFunc()
+	})
+}
+
+// RegisterContextDefaultFunc registers a default function
+// like RegisterDefaultFunc that gets the context of
+// the parsing, to stop on its cancellation.
+func RegisterContextDefaultFunc(name string, defaultFunc ContextDefaultFunc) error {
+	if name == "" {
+		return fmt.Errorf("invalid default function name %s", name)
+	}
+	if defaultFunc == nil {
+		return fmt.Errorf("default function %s is nil", name)
+	}
+	if defaultFuncs[name] != nil {
+		return fmt.Errorf("default function %s already registered", name)
+	}
+	defaultFuncs[name] = defaultFunc
+	return nil
+}
+
+// Returns the default value of the parameter, computed
+// by its default function if it has one.
+func (p *parameter) getDefaultValue() (string, error) {
+	if p.defaultFunc == "" {
+		return p.defaultValue, nil
+	}
+	value, err := defaultFuncs[p.defaultFunc](p.context())
+	if err != nil {
+		return "", fmt.Errorf("parameter %s : %s", p.name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,79 @@
+package yagclif
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterDefaultFunc(t *testing.T) {
+	defer func() {
+		delete(defaultFuncs, "currentUser")
+		delete(defaultFuncs, "failing")
+	}()
+	currentUser := func() (string, error) {
+		return "gopher", nil
+	}
+	failing := func() (string, error) {
+		return "", fmt.Errorf("no user")
+	}
+	t.Run("works", func(t *testing.T) {
+		assert.Nil(t, RegisterDefaultFunc("currentUser", currentUser))
+		assert.Nil(t, RegisterDefaultFunc("failing", failing))
+	})
+	t.Run("errors", func(t *testing.T) {
+		assert.NotNil(t, RegisterDefaultFunc("currentUser", currentUser))
+		assert.NotNil(t, RegisterDefaultFunc("", currentUser))
+		assert.NotNil(t, RegisterDefaultFunc("nil", nil))
+	})
+	t.Run("sets default", func(t *testing.T) {
+		type foo struct {
+			User  string `yagclif:"defaultfn:currentUser"`
+			Owner string `yagclif:"defaultfn:failing"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		assert.Equal(t, "--user string (default: currentUser())", params[0].GetHelp())
+		fooVar := &foo{}
+		found, err := params[0].setDefault(fooVar)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "gopher", fooVar.User)
+		_, err = params[1].setDefault(fooVar)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "no user")
+	})
+	t.Run("gets the context", func(t *testing.T) {
+		defer delete(defaultFuncs, "requestUser")
+		type key struct{}
+		requestUser := func(ctx context.Context) (string, error) {
+			return fmt.Sprint(ctx.Value(key{})), nil
+		}
+		assert.Nil(t, RegisterContextDefaultFunc("requestUser", requestUser))
+		assert.NotNil(t, RegisterContextDefaultFunc("requestUser", requestUser))
+		assert.NotNil(t, RegisterContextDefaultFunc("nil", nil))
+		type foo struct {
+			User string `yagclif:"defaultfn:requestUser"`
+		}
+		fooVar := &foo{}
+		ctx := context.WithValue(context.Background(), key{}, "gopher")
+		_, err := NewParser().ParseArgsContext(ctx, fooVar, []string{})
+		assert.Nil(t, err)
+		assert.Equal(t, &foo{User: "gopher"}, fooVar)
+	})
+	t.Run("constraint errors", func(t *testing.T) {
+		type foo struct {
+			User  string `yagclif:"defaultfn:unknown"`
+			Name  string `yagclif:"defaultfn:currentUser;default:me"`
+			Owner string `yagclif:"defaultfn:currentUser;mandatory"`
+		}
+		for i := 0; i < 3; i++ {
+			param, err := newParameter(reflect.TypeOf(foo{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+}
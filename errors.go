@@ -0,0 +1,38 @@
+package yagclif
+
+import "strings"
+
+// Errors are the problems found by a parsing,
+// they are reported together.
+type Errors []error
+
+// Error returns the problems one per line.
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\r\n")
+}
+
+// Adds the error, the problems of
+// Errors are added one by one.
+func (errs *Errors) add(err error) {
+	if nested, ok := err.(Errors); ok {
+		*errs = append(*errs, nested...)
+	} else if err != nil {
+		*errs = append(*errs, err)
+	}
+}
+
+// Returns nil without problems, the problem if
+// there is only one and the problems otherwise.
+func (errs Errors) err() error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errs
+}
@@ -0,0 +1,20 @@
+package yagclif
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	first, second := fmt.Errorf("first"), fmt.Errorf("second")
+	errs := Errors{}
+	assert.Nil(t, errs.err())
+	errs.add(nil)
+	errs.add(first)
+	assert.Equal(t, first, errs.err())
+	errs.add(Errors{second, first})
+	assert.Equal(t, Errors{first, second, first}, errs.err())
+	assert.EqualError(t, errs, "first\r\nsecond\r\nfirst")
+}
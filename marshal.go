@@ -0,0 +1,286 @@
+package yagclif
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal returns the arguments setting the fields of the object
+// to their current values, the fields equal to their default
+// values are left out. Secret fields are left out as well so
+// that they are not exposed in the arguments, mandatory or
+// positional ones are errors as the arguments would not parse
+// without them. Zero value commands can not tell if they were
+// selected, they are errors unless another command is selected.
+func Marshal(obj interface{}) ([]string, error) {
+	tipe := reflect.TypeOf(obj).Elem()
+	params, err := newParameters(tipe)
+	if err != nil {
+		return nil, err
+	}
+	return params.marshal(obj)
+}
+
+// Returns the arguments reproducing the object, its flags
+// followed by its operands and its selected command.
+func (params *parameters) marshal(obj interface{}) ([]string, error) {
+	tipe := reflect.TypeOf(obj).Elem()
+	// nil parent struct pointers are allocated in a copy.
+	copied := reflect.New(tipe)
+	copied.Elem().Set(reflect.ValueOf(obj).Elem())
+	defaults, err := params.defaultObject(tipe)
+	if err != nil {
+		return nil, err
+	}
+	flags, operands, command := []string{}, []string{}, []string{}
+	var zeroCommand *parameter
+	for _, param := range *params {
+		value := param.getValue(copied.Interface())
+		switch {
+		case param.secret && param.mandatory:
+			return nil, fmt.Errorf("parameter %s : mandatory secrets can not be marshaled", param.name)
+		case param.secret || param.positional:
+			continue
+		case param.command:
+			// zero struct pointers are the commands not selected.
+			if value.IsZero() && value.Kind() != reflect.Ptr && zeroCommand == nil {
+				zeroCommand = param
+			}
+			if len(command) > 0 || value.IsZero() {
+				continue
+			}
+			if value.Kind() != reflect.Ptr {
+				value = value.Addr()
+			}
+			commandArgs, err := param.commandParams.marshal(value.Interface())
+			if err != nil {
+				return nil, err
+			}
+			command = append([]string{param.commandNames()[0]}, commandArgs...)
+		case param.unknown:
+			flags = append(flags, value.Interface().([]string)...)
+		default:
+			if !param.mandatory && reflect.DeepEqual(value.Interface(), param.getValue(defaults).Interface()) {
+				continue
+			}
+			flag, err := param.marshalFlag(value)
+			if err != nil {
+				return nil, err
+			}
+			flags = append(flags, flag...)
+		}
+	}
+	if len(command) == 0 && zeroCommand != nil {
+		return nil, fmt.Errorf("command %s is not a pointer, its selection is ambiguous", zeroCommand.commandNames()[0])
+	}
+	// operands are given up to the last one set.
+	positionals := params.positionals()
+	last := -1
+	for i, param := range positionals {
+		value := param.getValue(copied.Interface())
+		if param.mandatory || !reflect.DeepEqual(value.Interface(), param.getValue(defaults).Interface()) {
+			last = i
+		}
+	}
+	commands := params.commands()
+	flagLike := false
+	for _, param := range positionals[:last+1] {
+		if param.secret {
+			return nil, fmt.Errorf("parameter %s : secret operands can not be marshaled", param.name)
+		}
+		values, err := param.marshalOperands(param.getValue(copied.Interface()))
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			flagLike = flagLike || (strings.HasPrefix(value, shortNamePrefix) && value != Stdio)
+			flagLike = flagLike || commands.findCommand(value) != nil
+		}
+		operands = append(operands, values...)
+	}
+	// operands looking like flags or commands follow the terminator.
+	if flagLike && len(command) > 0 {
+		return nil, fmt.Errorf("operands %s can not be followed by a command", operands)
+	} else if flagLike {
+		operands = append([]string{flagsTerminator}, operands...)
+	}
+	return append(append(flags, operands...), command...), nil
+}
+
+// Returns a new object holding the default values
+// of the constraints and of SetDefaults.
+func (params *parameters) defaultObject(tipe reflect.Type) (interface{}, error) {
+	obj := reflect.New(tipe).Interface()
+	for _, param := range *params {
+		if _, err := param.setTagDefault(obj); err != nil {
+			return nil, err
+		}
+	}
+	for _, nested := range params.nestedStructs(obj) {
+		if defaulter, ok := nested.(Defaulter); ok {
+			defaulter.SetDefaults()
+		}
+	}
+	return obj, nil
+}
+
+// Returns the arguments setting the flag to the value.
+func (p *parameter) marshalFlag(value reflect.Value) ([]string, error) {
+	name := p.CliNames()[0]
+	if p.count {
+		flags := []string{}
+		for i := int64(0); i < value.Int(); i++ {
+			flags = append(flags, name)
+		}
+		return flags, nil
+	}
+	if value.Kind() == reflect.Bool && value.Bool() {
+		return []string{name}, nil
+	}
+	formatted, err := p.formatValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprint(name, mapKeyValueDelimiter, formatted)}, nil
+}
+
+// Returns the operands setting the positional parameter
+// to the value, one per element for rest parameters.
+func (p *parameter) marshalOperands(value reflect.Value) ([]string, error) {
+	if !p.rest {
+		formatted, err := p.formatValue(value)
+		return []string{formatted}, err
+	}
+	elemParam := *p
+	elemParam.tipe = p.tipe.Elem()
+	elemParam.delimiter = p.subDelimiter
+	operands := []string{}
+	for i := 0; i < value.Len(); i++ {
+		formatted, err := elemParam.formatValue(value.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, formatted)
+	}
+	return operands, nil
+}
+
+// Returns the argument parsed into the value,
+// the reverse of the setter of the parameter.
+func (p *parameter) formatValue(value reflect.Value) (string, error) {
+	if p.isJSON() {
+		encoded, err := json.Marshal(value.Interface())
+		if err != nil {
+			return "", fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		return string(encoded), nil
+	}
+	if p.tipe == reflect.TypeOf(&time.Location{}) && !value.IsNil() {
+		return value.Interface().(*time.Location).String(), nil
+	}
+	if p.tipe.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", fmt.Errorf("parameter %s : nil can not be marshaled", p.name)
+		}
+		elemParam := *p
+		elemParam.tipe = p.tipe.Elem()
+		return elemParam.formatValue(value.Elem())
+	}
+	if isValue(p.tipe) {
+		return value.Addr().Interface().(Value).String(), nil
+	}
+	if isNullType(p.tipe) {
+		valueParam := *p
+		valueParam.tipe = p.baseType()
+		return valueParam.formatValue(value.Field(0))
+	}
+	switch p.tipe {
+	case reflect.TypeOf(time.Duration(0)):
+		return value.Interface().(time.Duration).String(), nil
+	case reflect.TypeOf(time.Time{}):
+		return value.Interface().(time.Time).Format(p.getLayout()), nil
+	case reflect.TypeOf(url.URL{}):
+		return value.Addr().Interface().(*url.URL).String(), nil
+	case reflect.TypeOf(regexp.Regexp{}):
+		return value.Addr().Interface().(*regexp.Regexp).String(), nil
+	case reflect.TypeOf([]byte{}):
+		return byteEncoders[p.encoding](value.Bytes()), nil
+	case reflect.TypeOf(map[string]string{}):
+		return p.formatMap(value)
+	}
+	if marshaler, ok := value.Addr().Interface().(encoding.TextMarshaler); ok && isTextUnmarshaler(p.tipe) {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		return string(text), nil
+	}
+	if p.IsArrayType() {
+		return p.formatArray(value)
+	}
+	if p.isRune {
+		return string(rune(value.Int())), nil
+	}
+	switch p.tipe.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, p.tipe.Bits()), nil
+	case reflect.String:
+		return value.String(), nil
+	}
+	return "", fmt.Errorf("parameter %s : %s can not be marshaled", p.name, p.tipe)
+}
+
+// Joins the formatted elements by the delimiter,
+// the delimiters of the elements are escaped.
+func (p *parameter) formatArray(value reflect.Value) (string, error) {
+	if value.Len() == 0 {
+		return "", fmt.Errorf("parameter %s : empty arrays can not be marshaled", p.name)
+	}
+	elemParam := *p
+	elemParam.tipe = p.tipe.Elem()
+	elemParam.delimiter = p.subDelimiter
+	parts := []string{}
+	for i := 0; i < value.Len(); i++ {
+		formatted, err := elemParam.formatValue(value.Index(i))
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, p.escape(formatted))
+	}
+	return strings.Join(parts, p.delimiter), nil
+}
+
+// Joins the key=value pairs sorted by key by the delimiter.
+func (p *parameter) formatMap(value reflect.Value) (string, error) {
+	if value.Len() == 0 {
+		return "", fmt.Errorf("parameter %s : empty maps can not be marshaled", p.name)
+	}
+	pairs := []string{}
+	for _, key := range value.MapKeys() {
+		pairs = append(pairs, p.escape(fmt.Sprint(key.String(), mapKeyValueDelimiter, value.MapIndex(key).String())))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, p.delimiter), nil
+}
+
+// Escapes the delimiters of the value.
+func (p *parameter) escape(value string) string {
+	if p.delimiter == "" {
+		return value
+	}
+	return strings.Replace(value, p.delimiter, escapeCharacter+p.delimiter, -1)
+}
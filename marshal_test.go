@@ -0,0 +1,152 @@
+package yagclif
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	type options struct {
+		Retries int
+	}
+	type get struct {
+		Key string `yagclif:"positional"`
+	}
+	type foo struct {
+		Src      string   `yagclif:"positional;mandatory"`
+		Files    []string `yagclif:"positional:rest"`
+		Name     string   `yagclif:"name:title"`
+		Level    int      `yagclif:"default:3"`
+		Ratio    float32
+		Verbose  bool
+		Color    bool `yagclif:"default:true"`
+		Quiet    *bool
+		Debug    int `yagclif:"count"`
+		Timeout  time.Duration
+		Since    time.Time         `yagclif:"layout:2006-01-02"`
+		Tags     []string          `yagclif:"delimiter:,"`
+		Labels   map[string]string `yagclif:"delimiter:,"`
+		Data     []byte            `yagclif:"encoding:hex"`
+		Endpoint url.URL
+		IP       net.IP
+		Size     ByteSize
+		Password string `yagclif:"secret"`
+		Options  *options
+		Get      *get `yagclif:"command"`
+	}
+	quiet := false
+	endpoint, _ := url.Parse("https://example.com/api")
+	source := &foo{
+		Src:      "a.txt",
+		Files:    []string{"b.txt", "c.txt"},
+		Name:     "my app",
+		Level:    0,
+		Ratio:    0.1,
+		Verbose:  true,
+		Color:    false,
+		Quiet:    &quiet,
+		Debug:    2,
+		Timeout:  time.Minute,
+		Since:    time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Tags:     []string{"a,b", "c"},
+		Labels:   map[string]string{"env": "prod", "team": "core"},
+		Data:     []byte{0xca, 0xfe},
+		Endpoint: *endpoint,
+		IP:       net.ParseIP("10.0.0.1"),
+		Size:     2048,
+		Password: "hunter2",
+		Options:  &options{Retries: 5},
+		Get:      &get{Key: "k"},
+	}
+	t.Run("works", func(t *testing.T) {
+		args, err := Marshal(source)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"--title=my app", "--level=0", "--ratio=0.1", "--verbose", "--color=false",
+			"--quiet=false", "--debug", "--debug", "--timeout=1m0s", "--since=2020-01-02",
+			`--tags=a\,b,c`, "--labels=env=prod,team=core", "--data=cafe",
+			"--endpoint=https://example.com/api", "--ip=10.0.0.1", "--size=2KiB",
+			"--options-retries=5", "a.txt", "b.txt", "c.txt", "get", "k",
+		}, args)
+		parsed := &foo{}
+		_, err = ParseArgs(parsed, args)
+		assert.Nil(t, err)
+		expected := *source
+		expected.Password = ""
+		assert.Equal(t, &expected, parsed)
+	})
+	t.Run("defaults are left out", func(t *testing.T) {
+		args, err := Marshal(&foo{Src: "a.txt", Level: 3, Color: true})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a.txt"}, args)
+	})
+	t.Run("operands looking like flags", func(t *testing.T) {
+		args, err := Marshal(&foo{Src: "-a.txt", Level: 3, Color: true})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--", "-a.txt"}, args)
+		_, err = Marshal(&foo{Src: "-a.txt", Level: 3, Color: true, Get: &get{}})
+		assert.NotNil(t, err)
+	})
+	t.Run("operands named like commands", func(t *testing.T) {
+		source := &foo{Src: "get", Level: 3, Color: true}
+		args, err := Marshal(source)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--", "get"}, args)
+		parsed := &foo{}
+		_, err = ParseArgs(parsed, args)
+		assert.Nil(t, err)
+		assert.Equal(t, source, parsed)
+		_, err = Marshal(&foo{Src: "get", Level: 3, Color: true, Get: &get{}})
+		assert.NotNil(t, err)
+	})
+	t.Run("mandatory secrets", func(t *testing.T) {
+		type bar struct {
+			Token string `yagclif:"secret;mandatory"`
+		}
+		source := &bar{Token: "hunter2"}
+		args, err := Marshal(source)
+		assert.NotNil(t, err)
+		assert.Nil(t, args)
+		// left out, the token would be missing.
+		_, err = ParseArgs(&bar{}, []string{})
+		assert.NotNil(t, err)
+	})
+	t.Run("zero value commands", func(t *testing.T) {
+		type version struct {
+			Short bool
+		}
+		type bar struct {
+			Version version `yagclif:"command"`
+			Get     *get    `yagclif:"command"`
+		}
+		parsed := &bar{}
+		_, err := ParseArgs(parsed, []string{"version"})
+		assert.Nil(t, err)
+		args, err := Marshal(parsed)
+		assert.NotNil(t, err)
+		assert.Nil(t, args)
+		args, err = Marshal(&bar{Get: &get{Key: "k"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"get", "k"}, args)
+		args, err = Marshal(&bar{Version: version{Short: true}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"version", "--short"}, args)
+	})
+	t.Run("return err", func(t *testing.T) {
+		type bar struct {
+			Tags []string `yagclif:"default:a"`
+		}
+		args, err := Marshal(&bar{})
+		assert.NotNil(t, err)
+		assert.Nil(t, args)
+		params, err := newParameters(reflect.TypeOf(bar{}))
+		assert.Nil(t, err)
+		_, err = params.marshal(&bar{Tags: []string{"b"}})
+		assert.Nil(t, err)
+	})
+}
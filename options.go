@@ -0,0 +1,70 @@
+package yagclif
+
+// Behavior of the parsing configured per parser,
+// the package variables are its default values.
+type parseOptions struct {
+	// Policy used to match the arguments with the cli names.
+	matching MatchingPolicy
+	// Strategy used to name the parameters.
+	naming NamingStrategy
+	// If false the flags end at the first operand.
+	interspersed bool
+	// If true the parsing stops at the first unknown flag.
+	stopAtUnknown bool
+	// If true flags can be written /name and /name:value.
+	windowsFlags bool
+	// If true long names can be abbreviated.
+	abbreviations bool
+	// If true long names can be written with a single hyphen.
+	singleDashLongNames bool
+	// Name of the environment variable whose
+	// arguments are prepended to the command line.
+	argsEnv string
+	// If true the POSIX utility syntax guidelines are enforced.
+	posix bool
+	// If true the getopt_long behavior of GNU tools is reproduced.
+	gnu bool
+	// If true the @file arguments are replaced
+	// by the lines of the file.
+	responseFiles bool
+}
+
+// Returns the options from the package variables.
+func defaultOptions() *parseOptions {
+	return &parseOptions{
+		matching:            matchingPolicy(),
+		naming:              Naming,
+		interspersed:        Interspersed,
+		stopAtUnknown:       StopAtUnknown,
+		windowsFlags:        WindowsFlags,
+		abbreviations:       Abbreviations,
+		singleDashLongNames: SingleDashLongNames,
+		argsEnv:             ArgsEnv,
+		posix:               POSIX,
+		gnu:                 GNU,
+		responseFiles:       ResponseFiles,
+	}
+}
+
+// Returns if flags and operands can be mixed.
+func (options *parseOptions) interspersedFlags() bool {
+	return (options.interspersed || options.gnu) && !options.posix
+}
+
+// Returns the options of the parameter, the ones
+// of the package variables if it has none.
+func (p *parameter) options() *parseOptions {
+	if p.parseOptions == nil {
+		return defaultOptions()
+	}
+	return p.parseOptions
+}
+
+// Returns the options of the parameters, the ones
+// of the package variables if they have none.
+func (params *parameters) options() *parseOptions {
+	if len(*params) == 0 {
+		return defaultOptions()
+	}
+	return (*params)[0].options()
+}
@@ -1,11 +1,26 @@
 package yagclif
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"database/sql"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Name of the tag to parse.
@@ -14,33 +29,282 @@ const tagName = "yagclif"
 // Value to prefix to name value.
 const namePrefix = "--"
 
+// Value to prefix to the name of
+// negatable parameters to set them to false.
+const negationPrefix = "--no-"
+
 // Value to prefix to shortName value.
 const shortNamePrefix = "-"
 
+// Value of the delimiter between the names
+// of nested struct fields.
+const nestedNameDelimiter = "-"
+
 // Value of the delimiter between constraint
 // key-value pairs.
 const constraintValueDelimiter = ":"
 
+// Value of the format constraint unmarshaling
+// the value with encoding/json.
+const jsonFormat = "json"
+
+// Default value of the delimiter between the
+// elements of two dimensional arrays.
+const defaultSubDelimiter = ","
+
+// Value of the delimiter between the values
+// of the choices and aliases constraints.
+const choicesDelimiter = "|"
+
+// Value of the positional constraint of array
+// parameters filled by all the arguments left.
+const restPositional = "rest"
+
+// Value shown instead of the values
+// of secret parameters.
+const secretMask = "****"
+
 // Value of the delimiter between constraints.
 const constraintsDelimiter = ";"
 
+// Layout used to parse time.Time values
+// when no layout constraint is set.
+const defaultTimeLayout = time.RFC3339
+
+// Named layouts usable with the layout constraint,
+// since the constraint syntax can not hold a colon.
+var namedTimeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC822":      time.RFC822,
+	"Kitchen":     time.Kitchen,
+}
+
+// Writer receiving the warnings emitted
+// when deprecated parameters are used.
+var WarningOutput io.Writer = os.Stderr
+
+// ErrNotConfirmed is returned by the parsing when the
+// confirmation of a parameter with the confirm constraint
+// is declined.
+var ErrNotConfirmed = errors.New("not confirmed")
+
+// Reader of the answers to the prompts of the
+// parameters with the prompt or confirm constraints.
+var PromptInput io.Reader = os.Stdin
+
+// Writer receiving the prompts of the parameters
+// with the prompt or confirm constraints.
+var PromptOutput io.Writer = os.Stderr
+
+// If true the names of every parameter keep the case
+// of the struct fields instead of being lower cased.
+//
+// Deprecated: set Matching to ExactCaseMatching or use
+// WithMatching, CaseSensitive is an alias of it.
+var CaseSensitive = false
+
+// NamingStrategy returns the cli name of a struct field
+// from its name, before the matching policy lower cases it.
+type NamingStrategy func(fieldName string) string
+
+// FieldNaming uses the struct field names,
+// MaxRetryCount is --maxretrycount.
+func FieldNaming(fieldName string) string {
+	return fieldName
+}
+
+// KebabCaseNaming separates the words of the struct field
+// names by hyphens, MaxRetryCount is --max-retry-count
+// and HTTPServer is --http-server.
+func KebabCaseNaming(fieldName string) string {
+	runes := []rune(fieldName)
+	var buffer bytes.Buffer
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				buffer.WriteString(nestedNameDelimiter)
+			}
+		}
+		buffer.WriteRune(unicode.ToLower(r))
+	}
+	return buffer.String()
+}
+
+// Strategy used to name the parameters without name constraint.
+var Naming NamingStrategy = FieldNaming
+
+// MatchingPolicy is the way the arguments are
+// matched with the cli names.
+type MatchingPolicy int
+
+const (
+	// LowerCaseMatching matches the arguments with
+	// the lower cased cli names.
+	LowerCaseMatching MatchingPolicy = iota
+	// ExactCaseMatching matches the arguments with cli names
+	// keeping the case of the struct fields.
+	ExactCaseMatching
+	// CaseInsensitiveMatching matches the arguments with
+	// the cli names whatever their case.
+	CaseInsensitiveMatching
+)
+
+// Policy used to match the arguments with the cli names,
+// parameters with the casesensitive constraint
+// are always matched with their exact case.
+var Matching = LowerCaseMatching
+
+// Returns the matching policy, ExactCaseMatching
+// when the deprecated CaseSensitive is set.
+func matchingPolicy() MatchingPolicy {
+	if CaseSensitive {
+		return ExactCaseMatching
+	}
+	return Matching
+}
+
+// If false the parsing of the flags stops at the first
+// argument that is not a flag, the arguments left are
+// positional or remaining arguments.
+var Interspersed = true
+
+// If true the parsing stops at the first unknown flag or
+// at the first argument left once the positional parameters
+// are filled, it and the following arguments are
+// returned unchanged as remaining arguments.
+var StopAtUnknown = false
+
+// If true flags can also be written /name and /name:value
+// as in windows command line tools.
+var WindowsFlags = false
+
+// If true long names can be abbreviated to any
+// unambiguous prefix, --verb matches --verbose.
+var Abbreviations = false
+
+// If true long names can also be written with a single
+// hyphen as with the flag package, -verbose matches
+// --verbose when no shortname is verbose.
+var SingleDashLongNames = false
+
+// Name of an environment variable whose arguments, split
+// like a shell does, are prepended to the arguments of
+// the command line by Parse and ParseCommand.
+var ArgsEnv = ""
+
+// If true the POSIX utility syntax guidelines are enforced :
+// flags have single character shortnames and no long names,
+// and the flags end at the first operand or at --.
+var POSIX = false
+
+// If true the getopt_long behavior of GNU tools is reproduced :
+// flags and operands can be mixed, long names can be abbreviated,
+// optional values must follow an = sign and grouped bool and
+// count shortnames are split, -vn5 is -v -n5.
+var GNU = false
+
+// Durations of the time units usable with the unit constraint.
+var timeUnits = map[string]time.Duration{
+	"ns":      time.Nanosecond,
+	"us":      time.Microsecond,
+	"ms":      time.Millisecond,
+	"s":       time.Second,
+	"seconds": time.Second,
+	"m":       time.Minute,
+	"minutes": time.Minute,
+	"h":       time.Hour,
+	"hours":   time.Hour,
+}
+
+// Returns the size of a unit of the unit constraint in nanoseconds
+// for time units or in bytes for size units (see ByteSize).
+func unitSize(unit string) (size int64, isTime bool, found bool) {
+	if duration, found := timeUnits[unit]; found {
+		return int64(duration), true, true
+	}
+	if unit == "bytes" {
+		return 1, false, true
+	}
+	size, found = byteSizeUnits[strings.ToLower(unit)]
+	return size, false, found && unit != ""
+}
+
+// Decoders of []byte values by encoding constraint value.
+var byteDecoders = map[string]func(s string) ([]byte, error){
+	"":       func(s string) ([]byte, error) { return []byte(s), nil },
+	"base64": base64.StdEncoding.DecodeString,
+	"hex":    hex.DecodeString,
+}
+
+// Encoders of []byte values by encoding constraint value.
+var byteEncoders = map[string]func(b []byte) string{
+	"":       func(b []byte) string { return string(b) },
+	"base64": base64.StdEncoding.EncodeToString,
+	"hex":    hex.EncodeToString,
+}
+
+// Values of the tag skipping the struct field.
+var omitTags = []string{"omit", "-"}
+
+// Returns if the tag skips the struct field.
+func isOmitted(tag string) bool {
+	for _, omitTag := range omitTags {
+		if tag == omitTag {
+			return true
+		}
+	}
+	return false
+}
+
 // Struct for stroring key-value string pair
 type keyValuePair struct {
 	key   string
 	value string
 }
 
-// Split a constraint as key-value constraint
+// Value prefixed to a delimiter of the
+// tag to use it in a constraint value.
+const escapeCharacter = `\`
+
+// Splits the string by the delimiters not
+// preceded by the escape character.
+func splitEscaped(s string, delimiter string) []string {
+	parts := []string{}
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], escapeCharacter+delimiter) {
+			i += len(escapeCharacter+delimiter) - 1
+		} else if strings.HasPrefix(s[i:], delimiter) {
+			parts = append(parts, s[start:i])
+			start = i + len(delimiter)
+			i = start - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// Removes the escape characters of the tag delimiters.
+func unescape(s string) string {
+	s = strings.Replace(s, escapeCharacter+constraintValueDelimiter, constraintValueDelimiter, -1)
+	return strings.Replace(s, escapeCharacter+constraintsDelimiter, constraintsDelimiter, -1)
+}
+
+// Split a constraint as key-value constraint,
+// escaped delimiters are unescaped.
 func splitConstraint(constraint string) (keyValuePair, error) {
-	parts := strings.Split(constraint, constraintValueDelimiter)
+	parts := splitEscaped(constraint, constraintValueDelimiter)
 	switch len(parts) {
 	case 1:
 		return keyValuePair{
-			parts[0], "",
+			unescape(parts[0]), "",
 		}, nil
 	case 2:
 		return keyValuePair{
-			parts[0], parts[1],
+			unescape(parts[0]), unescape(parts[1]),
 		}, nil
 	}
 	return keyValuePair{}, fmt.Errorf("syntax error too many characters %s ", constraintValueDelimiter)
@@ -51,10 +315,18 @@ type parameter struct {
 	// Name of the parameter arguments are tested
 	// by appending an underscore to this value.
 	name string
+	// Name of the parameter in the cli
+	// used instead of name if set.
+	cliName string
+	// Other names of the parameter in the cli.
+	aliases []string
 	// ShortName of the parameter argument.
 	// ShortName matches are evaluated after
 	// appending two underscore to this value.
 	shortName string
+	// Struct fields containing this parameter
+	// when nested or embedded in a struct.
+	parents []reflect.StructField
 	// Index of the structField this parameter
 	// was created from.
 	index int
@@ -69,40 +341,281 @@ type parameter struct {
 	used bool
 	// Value used to parse array types.
 	delimiter string
-	// Type of the parameter only types
-	// bool,int,string,[]int,[]string are supported.
+	// Value used to parse the elements
+	// of two dimensional array types.
+	subDelimiter string
+	// Layout used to parse time.Time types.
+	layout string
+	// Scheme required for url.URL types.
+	scheme string
+	// Values allowed for string and int types.
+	choices []string
+	// Lowest value allowed for numeric types.
+	min *float64
+	// Highest value allowed for numeric types.
+	max *float64
+	// Regular expression string values must match.
+	pattern *regexp.Regexp
+	// Lowest length allowed for string and array types.
+	minLen *int
+	// Highest length allowed for string and array types.
+	maxLen *int
+	// Encoding of []byte types.
+	encoding string
+	// If true the int value is incremented
+	// each time the parameter is found.
+	count bool
+	// If true the int32 value is parsed as a single
+	// unicode character, as reflect can not tell
+	// a rune from an int32.
+	isRune bool
+	// Format of the value, json values are
+	// unmarshaled into any type.
+	format string
+	// If true the string value must be
+	// the path of an existing file.
+	file bool
+	// If true the string value must be
+	// the path of an existing directory.
+	dir bool
+	// Type of the parameter only types of kinds
+	// bool,int*,uint*,float*,string and types
+	// time.Duration,time.Time,*time.Location,url.URL,regexp.Regexp,
+	// []byte,map[string]string,sql.Null*, types implementing
+	// Value or encoding.TextUnmarshaler, slices,
+	// two dimensional slices and pointers of those
+	// types are supported. Any type is supported
+	// with the json format.
 	tipe reflect.Type
 	// Default Value
 	defaultValue string
+	// Name of the registered function
+	// computing the default value.
+	defaultFunc string
+	// Value used when the parameter is
+	// found without value.
+	implicitValue string
+	// Name of the environment variable used
+	// when the parameter is not found.
+	env string
+	// If true the value was set from
+	// the environment variable.
+	setByEnv bool
+	// Behavior of the parsing shared by the parameters
+	// of a parser, the package variables if nil.
+	parseOptions *parseOptions
+	// If true the value was set from the
+	// arguments of the ArgsEnv variable.
+	fromEnvArgs bool
+	// Value replaced by the arguments of the ArgsEnv
+	// variable, restored when the command line sets it.
+	beforeEnvArgs reflect.Value
+	// Settings of the running parsing, its context
+	// and writers are used by the hooks.
+	settings *parseSettings
+	// Callbacks called when the parameter is set
+	// from the arguments, by order of registration.
+	callbacks []FieldCallback
+	// If true a warning is emitted
+	// when the parameter is used.
+	deprecated bool
+	// Message of the deprecation warning.
+	deprecation string
+	// If true the parameter is parsed
+	// but not shown in the help.
+	hidden bool
+	// If true the bool parameter is set to
+	// false by its name prefixed by no-.
+	negatable bool
+	// Names of the parameters that must be
+	// set when this parameter is used.
+	requires []string
+	// Names of the parameters that can not
+	// be used with this parameter.
+	conflicts []string
+	// Section of the help the
+	// parameter is shown in.
+	group string
+	// Name of the value shown in the
+	// help instead of the type name.
+	placeholder string
+	// If true the parameter is filled by an argument
+	// not matching any cli name instead of a flag.
+	positional bool
+	// If true the positional array parameter is
+	// filled by all the arguments left.
+	rest bool
+	// Order of the positional parameter, positional
+	// parameters without order follow the others.
+	order *int
+	// If true the value is never shown in
+	// the help and error messages.
+	secret bool
+	// If true the environment variables of the
+	// value are expanded before parsing it.
+	expandEnv bool
+	// If true the cli names keep the case
+	// of the names instead of being lower cased.
+	caseSensitive bool
+	// If true the array parameter can be used
+	// several times, appending the values,
+	// true for every non json array.
+	repeatable bool
+	// Names of the registered validators
+	// checking the value before parsing it.
+	validators []string
+	// Example of usage shown in the help.
+	example string
+	// If true the value is read from PromptInput
+	// when the parameter is not found.
+	prompt bool
+	// Question confirmed by the user
+	// when the parameter is used.
+	confirm string
+	// Unit of the int value, arguments with
+	// other units are converted to it.
+	unit string
+	// Name of the group of parameters
+	// of which at least one must be set.
+	oneOf string
+	// Name of the group of parameters
+	// of which exactly one must be set.
+	xor string
+	// If true the struct parameter is a command
+	// selected by its name in the arguments.
+	command bool
+	// Parameters of the struct of a command.
+	commandParams parameters
+	// If set, overrides for the arguments of the command
+	// if flags can follow its operands.
+	interspersed *bool
+	// If true the []string parameter is filled by
+	// the flags not matching any parameter.
+	unknown bool
+	// Policy for the keys of map types given
+	// several times : error, first or last.
+	duplicates string
+	// Lowest number of arguments of
+	// a rest positional parameter.
+	minArity *int
+	// Highest number of arguments of
+	// a rest positional parameter.
+	maxArity *int
 }
 
 // Returns Cli names (text before the parameter)
 // as lowercase strings.
 func (p *parameter) CliNames() []string {
+	// commands are named without prefix.
+	if p.command {
+		return p.commandNames()
+	}
+	// positional parameters are named by their upper cased name.
+	if p.rest {
+		return []string{strings.ToUpper(p.longName()) + "..."}
+	} else if p.positional || p.unknown {
+		return []string{strings.ToUpper(p.longName())}
+	}
+	names := []string{
+		fmt.Sprint(namePrefix, p.cased(p.longName())),
+	}
+	for _, alias := range p.aliases {
+		names = append(names, fmt.Sprint(namePrefix, p.cased(p.prefixName(alias))))
+	}
 	if p.hasShortName() {
-		return []string{
-			fmt.Sprint(namePrefix, strings.ToLower(p.name)),
-			fmt.Sprint(shortNamePrefix, strings.ToLower(p.shortName)),
-		}
+		names = append(names, fmt.Sprint(shortNamePrefix, p.cased(p.shortName)))
+	}
+	return names
+}
+
+// Returns the name lower cased unless the parameter
+// or every parameter is case sensitive.
+func (p *parameter) cased(name string) string {
+	if p.caseSensitive || p.options().matching == ExactCaseMatching {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// Returns if the argument is the cli name,
+// ignoring the case with CaseInsensitiveMatching.
+func (p *parameter) matchesName(name string, s string) bool {
+	if p.options().matching == CaseInsensitiveMatching && !p.caseSensitive {
+		return strings.EqualFold(name, s)
+	}
+	return name == s
+}
+
+// Returns the Cli names of the names of other
+// parameters as used in constraints.
+func (p *parameter) cliNamesOf(names []string) string {
+	cliNames := []string{}
+	for _, name := range names {
+		cliNames = append(cliNames, fmt.Sprint(namePrefix, strings.ToLower(p.options().naming(name))))
+	}
+	return strings.Join(cliNames, " ")
+}
+
+// Returns the Cli names setting a negatable parameter to false.
+func (p *parameter) NegatedNames() []string {
+	if !p.negatable {
+		return nil
 	}
 	return []string{
-		fmt.Sprint(namePrefix, strings.ToLower(p.name)),
+		fmt.Sprint(negationPrefix, p.cased(p.longName())),
+	}
+}
+
+// Returns the name prefixed by the names of
+// its parents struct fields.
+func (p *parameter) longName() string {
+	if p.cliName != "" {
+		return p.prefixName(p.cliName)
+	}
+	return p.prefixName(p.options().naming(p.name))
+}
+
+// Prefixes the name by the names of its parents struct fields.
+// Embedded struct fields are promoted without prefix.
+func (p *parameter) prefixName(name string) string {
+	names := []string{}
+	for _, parent := range p.parents {
+		if !parent.Anonymous {
+			names = append(names, p.options().naming(parent.Name))
+		}
 	}
+	names = append(names, name)
+	return strings.Join(names, nestedNameDelimiter)
 }
 
-// Splits a string by the delimiter.
+// Splits a string by the delimiter, a delimiter
+// preceded by the escape character is kept
+// in the element without the escape character.
 func (p *parameter) Split(s string) []string {
-	return strings.Split(s, p.delimiter)
+	parts := splitEscaped(s, p.delimiter)
+	for i, part := range parts {
+		parts[i] = strings.Replace(part, escapeCharacter+p.delimiter, p.delimiter, -1)
+	}
+	return parts
 }
 
 // Returns the help of a parameter.
 func (p *parameter) GetHelp() string {
+	if p.command {
+		return p.commandHelp()
+	}
 	var buffer bytes.Buffer
-	buffer.WriteString(strings.Join(p.CliNames(), " "))
+	buffer.WriteString(strings.Join(append(p.CliNames(), p.NegatedNames()...), " "))
 	buffer.WriteString(" ")
-	buffer.WriteString(p.tipe.String())
-	buffer.WriteString(" ")
-	if p.IsArrayType() {
+	// the collector of unknown flags takes no value.
+	if p.placeholder != "" {
+		buffer.WriteString(p.placeholder)
+		buffer.WriteString(" ")
+	} else if !p.unknown {
+		buffer.WriteString(p.typeName())
+		buffer.WriteString(" ")
+	}
+	if p.isDelimited() && !p.unknown {
 		buffer.WriteString("delimiter ")
 		if p.delimiter == " " {
 			buffer.WriteString("whitespace ")
@@ -112,12 +625,140 @@ func (p *parameter) GetHelp() string {
 			buffer.WriteString(" ")
 		}
 	}
+	if p.baseType() == reflect.TypeOf(time.Time{}) {
+		buffer.WriteString("layout ")
+		buffer.WriteString(p.getLayout())
+		buffer.WriteString(" ")
+	}
+	if len(p.choices) > 0 {
+		buffer.WriteString("choices ")
+		buffer.WriteString(strings.Join(p.choices, choicesDelimiter))
+		buffer.WriteString(" ")
+	}
+	if p.min != nil {
+		buffer.WriteString("min ")
+		buffer.WriteString(formatFloat(*p.min))
+		buffer.WriteString(" ")
+	}
+	if p.max != nil {
+		buffer.WriteString("max ")
+		buffer.WriteString(formatFloat(*p.max))
+		buffer.WriteString(" ")
+	}
+	if p.pattern != nil {
+		buffer.WriteString("pattern ")
+		buffer.WriteString(p.pattern.String())
+		buffer.WriteString(" ")
+	}
+	if p.minLen != nil {
+		buffer.WriteString("minlen ")
+		buffer.WriteString(strconv.Itoa(*p.minLen))
+		buffer.WriteString(" ")
+	}
+	if p.maxLen != nil {
+		buffer.WriteString("maxlen ")
+		buffer.WriteString(strconv.Itoa(*p.maxLen))
+		buffer.WriteString(" ")
+	}
+	if p.minArity != nil {
+		buffer.WriteString("arguments ")
+		buffer.WriteString(p.arityText())
+		buffer.WriteString(" ")
+	}
+	if p.isMatrixType() {
+		buffer.WriteString("subdelimiter ")
+		buffer.WriteString(p.subDelimiter)
+		buffer.WriteString(" ")
+	}
+	if p.format != "" {
+		buffer.WriteString("format ")
+		buffer.WriteString(p.format)
+		buffer.WriteString(" ")
+	}
+	if p.encoding != "" {
+		buffer.WriteString("encoding ")
+		buffer.WriteString(p.encoding)
+		buffer.WriteString(" ")
+	}
+	if p.unit != "" {
+		buffer.WriteString("unit ")
+		buffer.WriteString(p.unit)
+		buffer.WriteString(" ")
+	}
+	if p.scheme != "" {
+		buffer.WriteString("scheme ")
+		buffer.WriteString(p.scheme)
+		buffer.WriteString(" ")
+	}
+	if p.count {
+		buffer.WriteString("(count)")
+		buffer.WriteString(" ")
+	}
+	if p.file {
+		buffer.WriteString("(existing file)")
+		buffer.WriteString(" ")
+	}
+	if p.dir {
+		buffer.WriteString("(existing directory)")
+		buffer.WriteString(" ")
+	}
+	if p.deprecated {
+		buffer.WriteString("(DEPRECATED")
+		if p.deprecation != "" {
+			buffer.WriteString(": ")
+			buffer.WriteString(p.deprecation)
+		}
+		buffer.WriteString(") ")
+	}
+	if len(p.requires) > 0 {
+		buffer.WriteString("(requires ")
+		buffer.WriteString(p.cliNamesOf(p.requires))
+		buffer.WriteString(") ")
+	}
+	if len(p.conflicts) > 0 {
+		buffer.WriteString("(conflicts with ")
+		buffer.WriteString(p.cliNamesOf(p.conflicts))
+		buffer.WriteString(") ")
+	}
+	if p.oneOf != "" {
+		buffer.WriteString("(one of ")
+		buffer.WriteString(p.oneOf)
+		buffer.WriteString(") ")
+	}
+	if p.xor != "" {
+		buffer.WriteString("(exactly one of ")
+		buffer.WriteString(p.xor)
+		buffer.WriteString(") ")
+	}
 	if p.mandatory {
 		buffer.WriteString("(mandatory)")
 		buffer.WriteString(" ")
 	}
-	if p.defaultValue != "" {
-		buffer.WriteString("(default = ")
+	if p.env != "" {
+		buffer.WriteString("(env: ")
+		buffer.WriteString(p.env)
+		buffer.WriteString(") ")
+	}
+	if p.implicitValue != "" {
+		buffer.WriteString("(value optional, implicit: ")
+		buffer.WriteString(p.implicitValue)
+		buffer.WriteString(") ")
+	}
+	if p.example != "" {
+		buffer.WriteString("(example: ")
+		buffer.WriteString(p.example)
+		buffer.WriteString(") ")
+	}
+	if p.defaultFunc != "" {
+		buffer.WriteString("(default: ")
+		buffer.WriteString(p.defaultFunc)
+		buffer.WriteString("())")
+	} else if p.defaultValue != "" && p.secret {
+		buffer.WriteString("(default: ")
+		buffer.WriteString(secretMask)
+		buffer.WriteString(")")
+	} else if p.defaultValue != "" {
+		buffer.WriteString("(default: ")
 		buffer.WriteString(p.defaultValue)
 		buffer.WriteString(")")
 	}
@@ -128,6 +769,17 @@ func (p *parameter) GetHelp() string {
 	return buffer.String()
 }
 
+// Returns the layout used to parse time.Time values.
+func (p *parameter) getLayout() string {
+	if p.layout == "" {
+		return defaultTimeLayout
+	}
+	if namedLayout, found := namedTimeLayouts[p.layout]; found {
+		return namedLayout
+	}
+	return p.layout
+}
+
 // Returns if a shortName has been defined.
 func (p *parameter) hasShortName() bool {
 	return p.shortName != ""
@@ -135,7 +787,21 @@ func (p *parameter) hasShortName() bool {
 
 // Returns if the parameter matches the string.
 func (p *parameter) Matches(s string) bool {
-	for _, name := range p.CliNames() {
+	if p.positional || p.command || p.unknown {
+		return false
+	}
+	for _, name := range append(p.CliNames(), p.NegatedNames()...) {
+		if p.matchesName(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns if the string is exactly one of
+// the cli names or negated names.
+func (p *parameter) hasName(s string) bool {
+	for _, name := range append(p.CliNames(), p.NegatedNames()...) {
 		if name == s {
 			return true
 		}
@@ -144,9 +810,88 @@ func (p *parameter) Matches(s string) bool {
 }
 
 func (p *parameter) IsArrayType() bool {
-	stringArrayType, intArrayType := reflect.TypeOf([]string{}), reflect.TypeOf([]int{})
-	t := p.tipe
-	return t == stringArrayType || t == intArrayType
+	return isArray(p.tipe)
+}
+
+// Returns if the type is a slice of a supported
+// non array type, []byte and custom types excepted.
+func isArray(tipe reflect.Type) bool {
+	if tipe.Kind() != reflect.Slice || tipe == reflect.TypeOf([]byte{}) {
+		return false
+	}
+	if isValue(tipe) || isTextUnmarshaler(tipe) {
+		return false
+	}
+	switch tipe.Elem().Kind() {
+	case reflect.Slice:
+		// only two dimensional arrays are supported.
+		return tipe.Elem().Elem().Kind() != reflect.Slice && isArray(tipe.Elem())
+	case reflect.Map, reflect.Ptr:
+		return false
+	}
+	return isSupported(tipe.Elem())
+}
+
+// Returns if the parameter is a two dimensional array.
+func (p *parameter) isMatrixType() bool {
+	return p.IsArrayType() && !p.isJSON() && isArray(p.tipe.Elem())
+}
+
+// Returns the type of the parameter value,
+// pointer types are dereferenced.
+func (p *parameter) valueType() reflect.Type {
+	if p.tipe.Kind() == reflect.Ptr {
+		return p.tipe.Elem()
+	}
+	return p.tipe
+}
+
+// Returns the name of the parameter type for the help.
+func (p *parameter) typeName() string {
+	if p.valueType() == reflect.TypeOf(ByteSize(0)) {
+		return "size"
+	}
+	if isNullType(p.tipe) {
+		return p.baseType().String()
+	}
+	if p.isRune && p.IsArrayType() {
+		return "[]rune"
+	} else if p.isRune {
+		return "rune"
+	}
+	return p.valueType().String()
+}
+
+// Returns the type of the parameter value, array
+// pointer and sql.Null* types are dereferenced.
+func (p *parameter) baseType() reflect.Type {
+	if p.IsArrayType() {
+		return p.tipe.Elem()
+	}
+	if isNullType(p.tipe) {
+		return p.tipe.Field(0).Type
+	}
+	return p.valueType()
+}
+
+// Returns if the parameter is a bool or a *bool.
+func (p *parameter) isBoolType() bool {
+	return p.valueType() == reflect.TypeOf(true)
+}
+
+// Returns if the parameter is a map type.
+func (p *parameter) isMapType() bool {
+	return p.tipe == reflect.TypeOf(map[string]string{})
+}
+
+// Returns if the parameter value is split by the delimiter.
+func (p *parameter) isDelimited() bool {
+	return (p.IsArrayType() || p.isMapType()) && !p.isJSON()
+}
+
+// Returns if the value is unmarshaled from json.
+func (p *parameter) isJSON() bool {
+	return p.format == jsonFormat
 }
 
 // Gets value of the object by reflect
@@ -155,7 +900,17 @@ func (p *parameter) getValue(obj interface{}) reflect.Value {
 	if objValue.Kind() == reflect.Ptr {
 		objValue = objValue.Elem()
 	}
-	fieldValue := objValue.FieldByName(p.name)
+	for _, parent := range p.parents {
+		objValue = objValue.Field(parent.Index[0])
+		// nil struct pointers are allocated.
+		if objValue.Kind() == reflect.Ptr {
+			if objValue.IsNil() {
+				objValue.Set(reflect.New(objValue.Type().Elem()))
+			}
+			objValue = objValue.Elem()
+		}
+	}
+	fieldValue := objValue.Field(p.index)
 	return fieldValue
 }
 
@@ -165,123 +920,1061 @@ func (p *parameter) setBool(target reflect.Value) func(value string) error {
 	return nil
 }
 
-func (p *parameter) setInt(target reflect.Value) func(value string) error {
+// Parses an amount with a unit and converts it to the unit
+// of the parameter, amounts without unit are not converted.
+func (p *parameter) setUnit(target reflect.Value) func(value string) error {
+	size, isTime, _ := unitSize(p.unit)
 	return func(value string) error {
-		intValue, err := strconv.Atoi(value)
+		amount, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
-			return err
+			var total int64
+			if isTime {
+				duration, err := time.ParseDuration(value)
+				if err != nil {
+					return fmt.Errorf("parameter %s : %s", p.name, err)
+				}
+				total = int64(duration)
+			} else {
+				var byteSize ByteSize
+				if err := byteSize.Set(value); err != nil {
+					return fmt.Errorf("parameter %s : %s", p.name, err)
+				}
+				total = int64(byteSize)
+			}
+			if total%size != 0 {
+				return fmt.Errorf("parameter %s : %s is not a whole number of %s", p.name, value, p.unit)
+			}
+			amount = total / size
+		}
+		switch target.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if amount < 0 || target.OverflowUint(uint64(amount)) {
+				return fmt.Errorf("parameter %s : %s is out of range", p.name, value)
+			}
+			target.SetUint(uint64(amount))
+		default:
+			if target.OverflowInt(amount) {
+				return fmt.Errorf("parameter %s : %s is out of range", p.name, value)
+			}
+			target.SetInt(amount)
 		}
-		target.SetInt(int64(intValue))
 		return nil
 	}
 }
-func (p *parameter) setString(target reflect.Value) func(value string) error {
+
+func (p *parameter) setInt(target reflect.Value) func(value string) error {
 	return func(value string) error {
-		target.SetString(value)
+		intValue, err := strconv.ParseInt(value, 10, p.tipe.Bits())
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		target.SetInt(intValue)
 		return nil
 	}
 }
-func (p *parameter) setStringArray(target reflect.Value) func(value string) error {
+func (p *parameter) setRune(target reflect.Value) func(value string) error {
 	return func(value string) error {
-		parts := p.Split(value)
-		target.Set(reflect.ValueOf(parts))
+		runes := []rune(value)
+		if len(runes) != 1 {
+			return fmt.Errorf("parameter %s : expected a single character but found %s", p.name, value)
+		}
+		target.SetInt(int64(runes[0]))
 		return nil
 	}
 }
-func (p *parameter) setIntArray(target reflect.Value) func(value string) error {
+func (p *parameter) setUint(target reflect.Value) func(value string) error {
 	return func(value string) error {
-		parts := p.Split(value)
-		intParts := []int{}
-		for _, i := range parts {
-			j, err := strconv.Atoi(i)
-			if err != nil {
-				return err
-			}
-			intParts = append(intParts, j)
+		if strings.HasPrefix(value, "-") {
+			return fmt.Errorf("parameter %s : negative value %s for unsigned type %s", p.name, value, p.tipe)
+		}
+		uintValue, err := strconv.ParseUint(value, 10, p.tipe.Bits())
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
 		}
-		target.Set(reflect.ValueOf(intParts))
+		target.SetUint(uintValue)
 		return nil
 	}
 }
-
-func (p *parameter) setterOnValue(target reflect.Value) func(value string) error {
-	switch p.tipe {
-	case reflect.TypeOf(true):
-		return p.setBool(target)
-	case reflect.TypeOf(1):
-		return p.setInt(target)
-	case reflect.TypeOf(""):
-		return p.setString(target)
-	case reflect.TypeOf([]string{}):
-		return p.setStringArray(target)
-	case reflect.TypeOf([]int{}):
-		return p.setIntArray(target)
+func (p *parameter) setFloat(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		floatValue, err := strconv.ParseFloat(value, p.tipe.Bits())
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		target.SetFloat(floatValue)
+		return nil
 	}
-	return nil
 }
-
-// fills an object with the desired value
-func (p *parameter) SetterCallback(obj interface{}) (func(value string) error, error) {
-	if p.used {
-		return nil, fmt.Errorf("%s used multiple times", p.name)
+func (p *parameter) setDuration(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		target.Set(reflect.ValueOf(duration))
+		return nil
 	}
-	p.used = true
-	target := p.getValue(obj)
-	setter := p.setterOnValue(target)
-	// no setter callback for bool type
-	if setter == nil && p.tipe != reflect.TypeOf(true) {
-		return nil, fmt.Errorf("Incompatible type")
+}
+func (p *parameter) setTime(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		timeValue, err := time.Parse(p.getLayout(), value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		target.Set(reflect.ValueOf(timeValue))
+		return nil
 	}
-	return setter, nil
 }
-
-func (p *parameter) setDefault(obj interface{}) (bool, error) {
-	defaultValue := p.defaultValue
-	if defaultValue != "" {
-		target := p.getValue(obj)
-		return true, p.setDefaultOnValue(target)
+func (p *parameter) setJSON(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		jsonValue := reflect.New(p.tipe)
+		if err := json.Unmarshal([]byte(value), jsonValue.Interface()); err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		target.Set(jsonValue.Elem())
+		return nil
 	}
-	return false, nil
 }
-
-func (p *parameter) setDefaultOnValue(value reflect.Value) error {
-	if p.defaultValue == "" {
+func (p *parameter) setLocation(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		location, err := time.LoadLocation(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		target.Set(reflect.ValueOf(location))
 		return nil
 	}
-	setter := p.setterOnValue(value)
-	return setter(p.defaultValue)
 }
-func (p *parameter) testDefaultValue() error {
-	setMockValue := func(value interface{}) error {
-		valueType := reflect.TypeOf(value)
-		mockValue := reflect.New(valueType).Elem()
-		return p.setDefaultOnValue(mockValue)
+func (p *parameter) setURL(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		urlValue, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		if p.scheme != "" && urlValue.Scheme != p.scheme {
+			return fmt.Errorf("parameter %s : expected scheme %s but found %s", p.name, p.scheme, value)
+		}
+		target.Set(reflect.ValueOf(*urlValue))
+		return nil
 	}
-	switch p.tipe {
-	case reflect.TypeOf(false):
-		return setMockValue(false)
-	case reflect.TypeOf(1):
-		return setMockValue(1)
-	case reflect.TypeOf(""):
-		return setMockValue("")
-	case reflect.TypeOf([]string{}):
-		return setMockValue([]string{})
-	case reflect.TypeOf([]int{}):
-		return setMockValue([]int{})
-	}
-	return fmt.Errorf("Incompatible type")
 }
-func (p *parameter) validate() error {
-	getError := func(s string) error {
+func (p *parameter) setRegexp(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		compiled, err := regexp.Compile(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : invalid pattern %s : %s", p.name, value, err)
+		}
+		target.Set(reflect.ValueOf(compiled).Elem())
+		return nil
+	}
+}
+func (p *parameter) setBytes(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		decoded, err := byteDecoders[p.encoding](value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		target.SetBytes(decoded)
+		return nil
+	}
+}
+func (p *parameter) setString(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		target.SetString(value)
+		return nil
+	}
+}
+
+func (p *parameter) setArray(target reflect.Value) func(value string) error {
+	elemParam := *p
+	elemParam.tipe = p.tipe.Elem()
+	elemParam.delimiter = p.subDelimiter
+	// the length of the array is checked, not its elements.
+	elemParam.minLen, elemParam.maxLen = nil, nil
+	return func(value string) error {
+		parts := p.Split(value)
+		array := reflect.MakeSlice(p.tipe, 0, len(parts))
+		// used repeatable arrays are appended to.
+		if p.repeatable && p.used {
+			array = reflect.AppendSlice(array, target)
+		}
+		for _, part := range parts {
+			elem := reflect.New(elemParam.tipe).Elem()
+			if err := elemParam.elemSetterOnValue(elem)(part); err != nil {
+				return err
+			}
+			array = reflect.Append(array, elem)
+		}
+		target.Set(array)
+		return nil
+	}
+}
+
+// Returns the setter of an array element,
+// bool elements are parsed instead of set to true.
+func (p *parameter) elemSetterOnValue(target reflect.Value) func(value string) error {
+	if !p.isBoolType() {
+		return p.setterOnValue(target)
+	}
+	return func(value string) error {
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		// nil bool pointers are allocated.
+		if target.Kind() == reflect.Ptr {
+			target.Set(reflect.New(p.valueType()))
+			target.Elem().SetBool(boolValue)
+			return nil
+		}
+		target.SetBool(boolValue)
+		return nil
+	}
+}
+
+// Value of the delimiter between a map key and its value.
+const mapKeyValueDelimiter = "="
+
+// Values of the duplicates constraint, a key given
+// several times is an error by default.
+const (
+	errorDuplicate = "error"
+	firstDuplicate = "first"
+	lastDuplicate  = "last"
+)
+
+func (p *parameter) setMap(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(p.tipe))
+		}
+		for _, part := range p.Split(value) {
+			keyValue := strings.SplitN(part, mapKeyValueDelimiter, 2)
+			if len(keyValue) != 2 {
+				return fmt.Errorf("parameter %s : expected key%svalue but found %s", p.name, mapKeyValueDelimiter, part)
+			}
+			key := reflect.ValueOf(keyValue[0])
+			if target.MapIndex(key).IsValid() {
+				switch p.duplicates {
+				case firstDuplicate:
+					continue
+				case lastDuplicate:
+				default:
+					return fmt.Errorf("parameter %s : duplicate key %s", p.name, keyValue[0])
+				}
+			}
+			target.SetMapIndex(key, reflect.ValueOf(keyValue[1]))
+		}
+		return nil
+	}
+}
+
+// Value is the interface of types controlling their own parsing.
+// It mirrors the flag.Value interface so existing
+// custom flag types can be reused as struct fields.
+type Value interface {
+	String() string
+	Set(string) error
+}
+
+// Type of the Value interface.
+var valueInterfaceType = reflect.TypeOf((*Value)(nil)).Elem()
+
+// Returns if a pointer to the type implements Value.
+func isValue(tipe reflect.Type) bool {
+	return reflect.PtrTo(tipe).Implements(valueInterfaceType)
+}
+
+// Delegates the parsing to the Set method of the target.
+func (p *parameter) setCustomValue(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		customValue := target.Addr().Interface().(Value)
+		if err := customValue.Set(value); err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		return nil
+	}
+}
+
+// Types of the sql.Null* structs. Their first field
+// holds the value and their Valid field is set to true
+// when the flag is found.
+var nullTypes = []reflect.Type{
+	reflect.TypeOf(sql.NullString{}),
+	reflect.TypeOf(sql.NullInt64{}),
+	reflect.TypeOf(sql.NullInt32{}),
+	reflect.TypeOf(sql.NullFloat64{}),
+	reflect.TypeOf(sql.NullBool{}),
+	reflect.TypeOf(sql.NullTime{}),
+}
+
+// Returns if the type is a sql.Null* type.
+func isNullType(tipe reflect.Type) bool {
+	for _, nullType := range nullTypes {
+		if nullType == tipe {
+			return true
+		}
+	}
+	return false
+}
+
+// Sets the value of a sql.Null* type and marks it as valid.
+func (p *parameter) setNull(target reflect.Value) func(value string) error {
+	valueParam := *p
+	valueParam.tipe = p.baseType()
+	setter := valueParam.elemSetterOnValue(target.Field(0))
+	return func(value string) error {
+		if err := setter(value); err != nil {
+			return err
+		}
+		target.FieldByName("Valid").SetBool(true)
+		return nil
+	}
+}
+
+// Type of the encoding.TextUnmarshaler interface.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Returns if a pointer to the type implements encoding.TextUnmarshaler.
+func isTextUnmarshaler(tipe reflect.Type) bool {
+	return reflect.PtrTo(tipe).Implements(textUnmarshalerType)
+}
+
+// Delegates the parsing to the UnmarshalText method of the target.
+func (p *parameter) setText(target reflect.Value) func(value string) error {
+	return func(value string) error {
+		unmarshaler := target.Addr().Interface().(encoding.TextUnmarshaler)
+		if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		return nil
+	}
+}
+
+// Allocates the value pointed by the target
+// and sets it once the value is parsed.
+func (p *parameter) setPointer(target reflect.Value) func(value string) error {
+	elemParam := *p
+	elemParam.tipe = p.tipe.Elem()
+	pointer := reflect.New(elemParam.tipe)
+	setter := elemParam.setterOnValue(pointer.Elem())
+	// no setter callback for bool type
+	if setter == nil {
+		if elemParam.isBoolType() {
+			target.Set(pointer)
+		}
+		return nil
+	}
+	return func(value string) error {
+		if err := setter(value); err != nil {
+			return err
+		}
+		target.Set(pointer)
+		return nil
+	}
+}
+
+func (p *parameter) setterOnValue(target reflect.Value) func(value string) error {
+	setter := p.typeSetterOnValue(target)
+	if setter == nil {
+		return nil
+	}
+	if (p.minLen != nil || p.maxLen != nil) && hasLength(p.tipe) {
+		setter = p.checkLength(setter, target)
+	}
+	// constraints of arrays are checked on each element.
+	if !p.IsArrayType() {
+		if (p.min != nil || p.max != nil) && isNumeric(p.tipe) {
+			setter = p.checkRange(setter, target)
+		}
+		if p.pattern != nil && p.tipe.Kind() == reflect.String {
+			setter = p.checkPattern(setter)
+		}
+		if len(p.choices) > 0 {
+			setter = p.checkChoices(setter)
+		}
+		if len(p.validators) > 0 {
+			setter = p.checkValidators(setter)
+		}
+	}
+	if p.secret {
+		setter = p.maskSecret(setter)
+	}
+	return setter
+}
+
+// Wraps the setter to replace the value by
+// the secret mask in the error messages.
+func (p *parameter) maskSecret(setter func(value string) error) func(value string) error {
+	return func(value string) error {
+		err := setter(value)
+		if err == nil || value == "" {
+			return err
+		}
+		prefix := fmt.Sprintf("parameter %s : ", p.name)
+		message := strings.TrimPrefix(err.Error(), prefix)
+		if len(message) < len(err.Error()) {
+			return errors.New(prefix + maskValue(message, value))
+		}
+		return errors.New(maskValue(message, value))
+	}
+}
+
+// Replaces the value by the secret mask where it appears
+// quoted or as a whole word in the message.
+func maskValue(message, value string) string {
+	message = strings.Replace(message, strconv.Quote(value), strconv.Quote(secretMask), -1)
+	isWordByte := func(b byte) bool {
+		return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+	}
+	var masked strings.Builder
+	for {
+		index := strings.Index(message, value)
+		if index < 0 {
+			break
+		}
+		end := index + len(value)
+		if (index > 0 && isWordByte(message[index-1])) || (end < len(message) && isWordByte(message[end])) {
+			masked.WriteString(message[:end])
+		} else {
+			masked.WriteString(message[:index] + secretMask)
+		}
+		message = message[end:]
+	}
+	masked.WriteString(message)
+	return masked.String()
+}
+
+// Wraps the setter to fail on values not matching the pattern.
+func (p *parameter) checkPattern(setter func(value string) error) func(value string) error {
+	return func(value string) error {
+		if !p.pattern.MatchString(value) {
+			return fmt.Errorf("parameter %s : %s does not match pattern %s",
+				p.name, value, p.pattern,
+			)
+		}
+		return setter(value)
+	}
+}
+
+// Returns if the type is of an int, uint or float kind.
+func isNumeric(tipe reflect.Type) bool {
+	switch tipe.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Returns if the type is a string, []byte or array type.
+func hasLength(tipe reflect.Type) bool {
+	return tipe.Kind() == reflect.String || tipe == reflect.TypeOf([]byte{}) || isArray(tipe)
+}
+
+// Wraps the setter to fail on values shorter than minlen
+// or longer than maxlen, strings length is counted in runes.
+func (p *parameter) checkLength(setter func(value string) error, target reflect.Value) func(value string) error {
+	return func(value string) error {
+		if err := setter(value); err != nil {
+			return err
+		}
+		length := target.Len()
+		if target.Kind() == reflect.String {
+			length = utf8.RuneCountInString(target.String())
+		}
+		if p.minLen != nil && length < *p.minLen {
+			return fmt.Errorf("parameter %s : length %d is lower than minlen %d",
+				p.name, length, *p.minLen,
+			)
+		}
+		if p.maxLen != nil && length > *p.maxLen {
+			return fmt.Errorf("parameter %s : length %d is greater than maxlen %d",
+				p.name, length, *p.maxLen,
+			)
+		}
+		return nil
+	}
+}
+
+// Returns if the value is of an int or uint kind
+// and not parsed as a time.Duration or custom type.
+func (p *parameter) isPlainInt() bool {
+	baseType := p.baseType()
+	if baseType.Kind() == reflect.Float32 || baseType.Kind() == reflect.Float64 {
+		return false
+	}
+	if baseType == reflect.TypeOf(time.Duration(0)) || isValue(baseType) || isTextUnmarshaler(baseType) {
+		return false
+	}
+	return isNumeric(baseType)
+}
+
+// Formats a float without trailing zeros.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Returns the range allowed by the min and max constraints.
+func (p *parameter) rangeText() string {
+	switch {
+	case p.min != nil && p.max != nil:
+		return fmt.Sprintf("between %s and %s", formatFloat(*p.min), formatFloat(*p.max))
+	case p.min != nil:
+		return fmt.Sprintf("at least %s", formatFloat(*p.min))
+	}
+	return fmt.Sprintf("at most %s", formatFloat(*p.max))
+}
+
+// Sets the arity of the value of the arity constraint :
+// N for exactly N, N+ for at least N and N-M
+// for between N and M arguments.
+func (p *parameter) parseArity(value string) error {
+	parts := strings.SplitN(strings.TrimSuffix(value, "+"), "-", 2)
+	min, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return err
+	}
+	p.minArity = &min
+	if len(parts) == 2 {
+		max, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return err
+		}
+		p.maxArity = &max
+	} else if !strings.HasSuffix(value, "+") {
+		p.maxArity = &min
+	}
+	return nil
+}
+
+// Returns the number of arguments allowed by the arity constraint.
+func (p *parameter) arityText() string {
+	switch {
+	case p.maxArity == nil:
+		return fmt.Sprintf("at least %d", *p.minArity)
+	case *p.minArity == *p.maxArity:
+		return strconv.Itoa(*p.minArity)
+	}
+	return fmt.Sprintf("between %d and %d", *p.minArity, *p.maxArity)
+}
+
+// Validates that the number of arguments of a rest
+// positional parameter matches its arity constraint.
+func (p *parameter) checkArity(obj interface{}) error {
+	if p.minArity == nil {
+		return nil
+	}
+	count := 0
+	if p.used {
+		count = p.getValue(obj).Len()
+	}
+	if count < *p.minArity || (p.maxArity != nil && count > *p.maxArity) {
+		return fmt.Errorf("%s : expected %s arguments, got %d",
+			p.CliNames()[0], p.arityText(), count,
+		)
+	}
+	return nil
+}
+
+// Validates the length of the rest positional parameter
+// once its arguments are appended one by one.
+func (p *parameter) checkRestLength(obj interface{}) error {
+	if !p.rest || !p.used {
+		return nil
+	}
+	noop := func(value string) error { return nil }
+	return p.checkLength(noop, p.getValue(obj))("")
+}
+
+// Wraps the setter to fail on values out of the
+// range of the min and max constraints.
+func (p *parameter) checkRange(setter func(value string) error, target reflect.Value) func(value string) error {
+	return func(value string) error {
+		if err := setter(value); err != nil {
+			return err
+		}
+		var number float64
+		switch target.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			number = float64(target.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			number = float64(target.Uint())
+		default:
+			number = target.Float()
+		}
+		if (p.min != nil && number < *p.min) || (p.max != nil && number > *p.max) {
+			return fmt.Errorf("parameter %s : %s is out of range, must be %s",
+				p.name, value, p.rangeText(),
+			)
+		}
+		return nil
+	}
+}
+
+// Wraps the setter to fail on values not in the choices.
+func (p *parameter) checkChoices(setter func(value string) error) func(value string) error {
+	return func(value string) error {
+		for _, choice := range p.choices {
+			if choice == value {
+				return setter(value)
+			}
+		}
+		return fmt.Errorf("parameter %s : %s is not one of %s",
+			p.name, value, strings.Join(p.choices, ", "),
+		)
+	}
+}
+
+func (p *parameter) typeSetterOnValue(target reflect.Value) func(value string) error {
+	if p.isJSON() {
+		return p.setJSON(target)
+	}
+	// locations are shared and only used as pointers.
+	if p.tipe == reflect.TypeOf(&time.Location{}) {
+		return p.setLocation(target)
+	}
+	if p.tipe.Kind() == reflect.Ptr {
+		return p.setPointer(target)
+	}
+	if isValue(p.tipe) {
+		return p.setCustomValue(target)
+	}
+	if isNullType(p.tipe) {
+		return p.setNull(target)
+	}
+	switch p.tipe {
+	case reflect.TypeOf(time.Duration(0)):
+		return p.setDuration(target)
+	case reflect.TypeOf(time.Time{}):
+		return p.setTime(target)
+	case reflect.TypeOf(url.URL{}):
+		return p.setURL(target)
+	case reflect.TypeOf(regexp.Regexp{}):
+		return p.setRegexp(target)
+	case reflect.TypeOf([]byte{}):
+		return p.setBytes(target)
+	case reflect.TypeOf(map[string]string{}):
+		return p.setMap(target)
+	}
+	if isTextUnmarshaler(p.tipe) {
+		return p.setText(target)
+	}
+	if p.IsArrayType() {
+		return p.setArray(target)
+	}
+	if p.isRune {
+		return p.setRune(target)
+	}
+	if p.unit != "" {
+		return p.setUnit(target)
+	}
+	switch p.tipe.Kind() {
+	case reflect.Bool:
+		return p.setBool(target)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return p.setInt(target)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return p.setUint(target)
+	case reflect.Float32, reflect.Float64:
+		return p.setFloat(target)
+	case reflect.String:
+		return p.setString(target)
+	}
+	return nil
+}
+
+// Writes the deprecation warning to the warning output.
+func (p *parameter) warnDeprecated() {
+	if p.deprecation == "" {
+		fmt.Fprintf(p.warningOutput(), "warning: %s is deprecated\n", p.CliNames()[0])
+		return
+	}
+	fmt.Fprintf(p.warningOutput(), "warning: %s is deprecated, %s\n", p.CliNames()[0], p.deprecation)
+}
+
+// fills an object with the desired value
+func (p *parameter) SetterCallback(obj interface{}) (func(value string) error, error) {
+	if p.deprecated {
+		p.warnDeprecated()
+	}
+	// counts are incremented without value.
+	if p.count {
+		p.used = true
+		target := p.getValue(obj)
+		target.SetInt(target.Int() + 1)
+		return nil, nil
+	}
+	// maps and repeatable arrays are filled by repeated usages.
+	if p.used && !p.isMapType() && !p.repeatable {
+		return nil, fmt.Errorf("%s used multiple times", p.name)
+	}
+	target := p.getValue(obj)
+	// the first usage replaces the default value.
+	if !p.used && p.isMapType() {
+		target.Set(reflect.MakeMap(p.tipe))
+	} else if !p.used && p.repeatable {
+		target.Set(reflect.Zero(p.tipe))
+	}
+	p.used = true
+	setter := p.setterOnValue(target)
+	// no setter callback for bool type
+	if setter == nil && !p.isBoolType() {
+		return nil, fmt.Errorf("Incompatible type")
+	}
+	if setter != nil && (p.file || p.dir) {
+		setter = p.checkPath(setter)
+	}
+	if setter != nil && p.expandEnv {
+		setter = p.expandValue(setter)
+	}
+	return setter, nil
+}
+
+// Appends the value to the array of the parameter,
+// the first value replaces the default value.
+func (p *parameter) appendValue(obj interface{}, value string) error {
+	target := p.getValue(obj)
+	if !p.used {
+		target.Set(reflect.Zero(p.tipe))
+	}
+	p.used = true
+	elemParam := *p
+	elemParam.tipe = p.tipe.Elem()
+	elemParam.delimiter = p.subDelimiter
+	elemParam.minLen, elemParam.maxLen = nil, nil
+	elem := reflect.New(elemParam.tipe).Elem()
+	if err := elemParam.elemSetterOnValue(elem)(value); err != nil {
+		return err
+	}
+	target.Set(reflect.Append(target, elem))
+	return nil
+}
+
+// Returns the context of the parsing,
+// the background one outside of parsings.
+func (p *parameter) context() context.Context {
+	if p.settings == nil {
+		return context.Background()
+	}
+	return p.settings.ctx
+}
+
+// Returns the writer of the warnings of the
+// parsing, WarningOutput outside of parsings.
+func (p *parameter) warningOutput() io.Writer {
+	if p.settings == nil || p.settings.warnings == nil {
+		return WarningOutput
+	}
+	return p.settings.warnings
+}
+
+// Reads a line of the input, the context of the parsing
+// interrupts the wait but not the reading which goes on
+// in the background until the line is read.
+func (p *parameter) readAnswer(input *bufio.Reader) (string, error) {
+	type answer struct {
+		line string
+		err  error
+	}
+	answers := make(chan answer, 1)
+	go func() {
+		line, err := input.ReadString('\n')
+		answers <- answer{line, err}
+	}()
+	select {
+	case <-p.context().Done():
+		return "", p.context().Err()
+	case read := <-answers:
+		return read.line, read.err
+	}
+}
+
+// Asks the confirmation question on PromptOutput,
+// only answers starting by y confirm.
+func (p *parameter) askConfirmation(input *bufio.Reader) error {
+	if err := p.context().Err(); err != nil {
+		return err
+	}
+	fmt.Fprintf(PromptOutput, "%s [y/N]: ", p.confirm)
+	answer, err := p.readAnswer(input)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("parameter %s : %w", p.name, err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if !strings.HasPrefix(answer, "y") {
+		return fmt.Errorf("%s %w", p.CliNames()[0], ErrNotConfirmed)
+	}
+	return nil
+}
+
+// Asks the value of the parameter on PromptOutput and sets
+// the answer read from the input, an empty answer keeps
+// the default value.
+func (p *parameter) promptValue(obj interface{}, input *bufio.Reader) error {
+	if err := p.context().Err(); err != nil {
+		return err
+	}
+	label := p.CliNames()[0]
+	if p.description != "" {
+		label = fmt.Sprintf("%s (%s)", label, p.description)
+	}
+	if p.defaultValue != "" && p.secret {
+		label = fmt.Sprintf("%s [%s]", label, secretMask)
+	} else if p.defaultValue != "" {
+		label = fmt.Sprintf("%s [%s]", label, p.defaultValue)
+	}
+	fmt.Fprintf(PromptOutput, "%s: ", label)
+	answer, err := p.readAnswer(input)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("parameter %s : %w", p.name, err)
+	}
+	answer = strings.TrimRight(answer, "\r\n")
+	if answer == "" {
+		return nil
+	}
+	setter := p.elemSetterOnValue(p.getValue(obj))
+	if p.file || p.dir {
+		setter = p.checkPath(setter)
+	}
+	if p.expandEnv {
+		setter = p.expandValue(setter)
+	}
+	if err := setter(answer); err != nil {
+		return err
+	}
+	p.used = true
+	return nil
+}
+
+// Wraps the setter to replace the environment
+// variables of the value (see os.ExpandEnv).
+func (p *parameter) expandValue(setter func(value string) error) func(value string) error {
+	return func(value string) error {
+		return setter(os.ExpandEnv(value))
+	}
+}
+
+// Returns if the argument is a negated name of the parameter.
+func (p *parameter) IsNegation(s string) bool {
+	for _, name := range p.NegatedNames() {
+		if p.matchesName(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sets the bool value to false, nil
+// bool pointers are allocated.
+func (p *parameter) Negate(obj interface{}) error {
+	if p.deprecated {
+		p.warnDeprecated()
+	}
+	if p.used {
+		return fmt.Errorf("%s used multiple times", p.name)
+	}
+	p.used = true
+	target := p.getValue(obj)
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(p.valueType()))
+		target = target.Elem()
+	}
+	target.SetBool(false)
+	return nil
+}
+
+// Wraps the setter to fail on paths that do not exist
+// or are not a file or a directory as expected.
+func (p *parameter) checkPath(setter func(value string) error) func(value string) error {
+	checkedSetter := func(value string) error {
+		// - is the standard input or output.
+		if p.file && value == Stdio {
+			return setter(value)
+		}
+		info, err := os.Stat(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s : %s", p.name, err)
+		}
+		if p.file && info.IsDir() {
+			return fmt.Errorf("parameter %s : %s is a directory", p.name, value)
+		}
+		if p.dir && !info.IsDir() {
+			return fmt.Errorf("parameter %s : %s is not a directory", p.name, value)
+		}
+		return setter(value)
+	}
+	if p.secret {
+		return p.maskSecret(checkedSetter)
+	}
+	return checkedSetter
+}
+
+func (p *parameter) setDefault(obj interface{}) (bool, error) {
+	if envValue, found := p.lookupEnv(); found {
+		return true, p.setEnv(obj, envValue)
+	}
+	return p.setTagDefault(obj)
+}
+
+// Sets the value of the default or defaultfn
+// constraint and returns if there is one.
+func (p *parameter) setTagDefault(obj interface{}) (bool, error) {
+	defaultValue, err := p.getDefaultValue()
+	if err != nil {
+		return false, err
+	}
+	if defaultValue != "" {
+		setter := p.elemSetterOnValue(p.getValue(obj))
+		// paths are checked at parse time only.
+		if p.file || p.dir {
+			setter = p.checkPath(setter)
+		}
+		if p.expandEnv {
+			setter = p.expandValue(setter)
+		}
+		return true, setter(defaultValue)
+	}
+	return false, nil
+}
+
+// Returns a copy of the value, the value
+// pointed by pointers is copied as well.
+func copyValue(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		copied.Set(reflect.New(value.Type().Elem()))
+		copied.Elem().Set(value.Elem())
+		return copied
+	}
+	copied.Set(value)
+	return copied
+}
+
+// Returns the value of the environment variable
+// of the parameter if it is set.
+func (p *parameter) lookupEnv() (string, bool) {
+	if p.env == "" {
+		return "", false
+	}
+	return os.LookupEnv(p.env)
+}
+
+// Sets the value of the environment variable,
+// bool values are parsed instead of set to true.
+func (p *parameter) setEnv(obj interface{}, envValue string) error {
+	p.setByEnv = true
+	setter := p.elemSetterOnValue(p.getValue(obj))
+	if p.file || p.dir {
+		setter = p.checkPath(setter)
+	}
+	if err := setter(envValue); err != nil {
+		return fmt.Errorf("%s (from environment variable %s)", err, p.env)
+	}
+	return nil
+}
+
+func (p *parameter) setDefaultOnValue(value reflect.Value) error {
+	if p.defaultValue == "" {
+		return nil
+	}
+	setter := p.elemSetterOnValue(value)
+	return setter(p.defaultValue)
+}
+func (p *parameter) testDefaultValue() error {
+	if !isSupported(p.tipe) && !p.isJSON() {
+		return fmt.Errorf("Incompatible type")
+	}
+	mockValue := reflect.New(p.tipe).Elem()
+	if p.implicitValue != "" {
+		if err := p.setterOnValue(mockValue)(p.implicitValue); err != nil {
+			return err
+		}
+		mockValue = reflect.New(p.tipe).Elem()
+	}
+	return p.setDefaultOnValue(mockValue)
+}
+func (p *parameter) validate() error {
+	getError := func(s string) error {
 		return fmt.Errorf("parameter %s : %s",
 			p.name, s,
 		)
 	}
-	if (p.mandatory || p.tipe == reflect.TypeOf(true)) && p.defaultValue != "" {
+	if p.command {
+		return p.validateCommand()
+	} else if p.interspersed != nil {
+		return getError("interspersed on non command parameter")
+	}
+	if p.mandatory && (p.defaultValue != "" || p.defaultFunc != "") {
 		return getError("can not be mandatory or have a default value")
-	} else if !p.IsArrayType() && strings.Trim(p.delimiter, " ") != "" {
+	} else if p.implicitValue != "" && (p.isBoolType() || p.count || p.positional) {
+		return getError("implicit value on bool, count or positional parameter")
+	} else if p.defaultValue != "" && p.defaultFunc != "" {
+		return getError("can not have both a default value and a default function")
+	} else if !p.isDelimited() && strings.Trim(p.delimiter, " ") != "" {
 		return getError("delimiter on non array type")
-	} else if p.mandatory && p.tipe == reflect.TypeOf(true) {
+	} else if !p.isMatrixType() && p.subDelimiter != "" {
+		return getError("subdelimiter on non two dimensional array type")
+	} else if p.layout != "" && p.baseType() != reflect.TypeOf(time.Time{}) {
+		return getError("layout on non time.Time type")
+	} else if p.scheme != "" && p.baseType() != reflect.TypeOf(url.URL{}) {
+		return getError("scheme on non url.URL type")
+	} else if len(p.choices) > 0 && p.baseType() != reflect.TypeOf("") && p.baseType() != reflect.TypeOf(1) {
+		return getError("choices on non string or int type")
+	} else if (p.min != nil || p.max != nil) && !isNumeric(p.baseType()) {
+		return getError("min or max on non numeric type")
+	} else if p.min != nil && p.max != nil && *p.min > *p.max {
+		return getError("min greater than max")
+	} else if p.pattern != nil && p.baseType().Kind() != reflect.String {
+		return getError("pattern on non string type")
+	} else if (p.minLen != nil || p.maxLen != nil) && !hasLength(p.valueType()) && !hasLength(p.baseType()) {
+		return getError("minlen or maxlen on non string or array type")
+	} else if p.minLen != nil && p.maxLen != nil && *p.minLen > *p.maxLen {
+		return getError("minlen greater than maxlen")
+	} else if p.isRune && p.baseType().Kind() != reflect.Int32 {
+		return getError("rune on non rune type")
+	} else if p.format != "" && !p.isJSON() {
+		return getError(fmt.Sprintf("unknown format %s", p.format))
+	} else if (p.file || p.dir) && p.valueType().Kind() != reflect.String {
+		return getError("file or dir on non string type")
+	} else if p.file && p.dir {
+		return getError("can not be both a file and a dir")
+	} else if p.count && p.tipe != reflect.TypeOf(1) {
+		return getError("count on non int type")
+	} else if p.encoding != "" && p.tipe != reflect.TypeOf([]byte{}) {
+		return getError("encoding on non []byte type")
+	} else if p.duplicates != "" && !p.isMapType() {
+		return getError("duplicates on non map type")
+	} else if p.duplicates != "" && p.duplicates != errorDuplicate && p.duplicates != firstDuplicate && p.duplicates != lastDuplicate {
+		return getError(fmt.Sprintf("unknown duplicates policy %s", p.duplicates))
+	} else if _, found := byteDecoders[p.encoding]; !found {
+		return getError(fmt.Sprintf("unknown encoding %s", p.encoding))
+	} else if p.positional && (p.isBoolType() || p.count || p.negatable) {
+		return getError("positional on bool or count type")
+	} else if p.rest && (!p.IsArrayType() || p.isJSON()) {
+		return getError("rest positional on non array type")
+	} else if p.unknown && p.tipe != reflect.TypeOf([]string{}) {
+		return getError("unknown on non []string type")
+	} else if p.unknown && (p.positional || p.shortName != "" || len(p.aliases) > 0) {
+		return getError("unknown can not be positional or have a shortname or aliases")
+	} else if p.minArity != nil && !p.rest {
+		return getError("arity on non rest positional parameter")
+	} else if p.minArity != nil && p.maxArity != nil && *p.minArity > *p.maxArity {
+		return getError("arity minimum greater than maximum")
+	} else if p.positional && (p.shortName != "" || len(p.aliases) > 0) {
+		return getError("positional can not have a shortname or aliases")
+	} else if _, _, found := unitSize(p.unit); p.unit != "" && !found {
+		return getError(fmt.Sprintf("unknown unit %s", p.unit))
+	} else if p.unit != "" && !p.isPlainInt() {
+		return getError("unit on non int type")
+	} else if p.prompt && (p.count || p.positional) {
+		return getError("prompt on count or positional parameter")
+	} else if p.repeatable && (!p.IsArrayType() || p.isJSON()) {
+		return getError("repeatable on non array type")
+	} else if p.negatable && !p.isBoolType() {
+		return getError("negatable on non bool type")
+	} else if p.mandatory && p.isBoolType() {
 		return getError("boolean type can not be mandatory")
 	}
 	return p.testDefaultValue()
@@ -298,20 +1991,226 @@ func (p *parameter) fillParameter(constraint string) error {
 	case "description":
 		p.description = value
 		return nil
+	case "name":
+		if value == "" {
+			return fmt.Errorf("empty name")
+		}
+		p.cliName = value
+		return nil
+	case "aliases":
+		p.aliases = strings.Split(value, choicesDelimiter)
+		for _, alias := range p.aliases {
+			if alias == "" {
+				return fmt.Errorf("empty alias")
+			}
+		}
+		return nil
 	case "shortname":
 		p.shortName = value
 		return nil
+	case "requires":
+		p.requires = strings.Split(value, choicesDelimiter)
+		return nil
+	case "conflicts":
+		p.conflicts = strings.Split(value, choicesDelimiter)
+		return nil
+	case "negatable":
+		p.negatable = true
+		return nil
+	case "oneof":
+		if value == "" {
+			return fmt.Errorf("empty oneof group")
+		}
+		p.oneOf = value
+		return nil
+	case "xor":
+		if value == "" {
+			return fmt.Errorf("empty xor group")
+		}
+		p.xor = value
+		return nil
+	case "unit":
+		p.unit = value
+		return nil
+	case "confirm":
+		if value == "" {
+			return fmt.Errorf("empty confirmation")
+		}
+		p.confirm = value
+		return nil
+	case "prompt":
+		p.prompt = true
+		return nil
+	case "example":
+		p.example = value
+		return nil
+	case "validator":
+		p.validators = strings.Split(value, choicesDelimiter)
+		for _, name := range p.validators {
+			if validators[name] == nil {
+				return fmt.Errorf("unknown validator %s", name)
+			}
+		}
+		return nil
+	// arrays are always repeatable, the
+	// constraint is kept for compatibility.
+	case "repeatable":
+		p.repeatable = true
+		return nil
+	case "casesensitive":
+		p.caseSensitive = true
+		return nil
+	case "expandenv":
+		p.expandEnv = true
+		return nil
+	case "secret":
+		p.secret = true
+		return nil
+	case "interspersed":
+		interspersed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		p.interspersed = &interspersed
+		return nil
+	case "command":
+		if value != "" {
+			return fmt.Errorf("unknown command value %s", value)
+		}
+		p.command = true
+		return nil
+	case "unknown":
+		p.unknown = true
+		return nil
+	case "positional":
+		p.positional = true
+		p.rest = value == restPositional
+		if value == "" || p.rest {
+			return nil
+		}
+		order, err := strconv.Atoi(value)
+		if err != nil || order < 0 {
+			return fmt.Errorf("unknown positional value %s", value)
+		}
+		p.order = &order
+		return nil
+	case "placeholder":
+		p.placeholder = value
+		return nil
+	case "group":
+		p.group = value
+		return nil
+	case "hidden":
+		p.hidden = true
+		return nil
+	case "deprecated":
+		p.deprecated = true
+		p.deprecation = value
+		return nil
 	case "mandatory":
 		p.mandatory = true
 		return nil
 	case "default":
 		p.defaultValue = value
 		return nil
+	case "implicit":
+		if value == "" {
+			return fmt.Errorf("empty implicit value")
+		}
+		p.implicitValue = value
+		return nil
+	case "defaultfn":
+		if defaultFuncs[value] == nil {
+			return fmt.Errorf("unknown default function %s", value)
+		}
+		p.defaultFunc = value
+		return nil
+	case "env":
+		p.env = value
+		return nil
 	case "delimiter":
 		p.delimiter = value
 		return nil
+	case "subdelimiter":
+		p.subDelimiter = value
+		return nil
+	case "layout":
+		p.layout = value
+		return nil
+	case "scheme":
+		p.scheme = value
+		return nil
+	case "choices":
+		p.choices = strings.Split(value, choicesDelimiter)
+		return nil
+	case "encoding":
+		p.encoding = value
+		return nil
+	case "duplicates":
+		p.duplicates = value
+		return nil
+	case "min", "max":
+		bound, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if key == "min" {
+			p.min = &bound
+		} else {
+			p.max = &bound
+		}
+		return nil
+	case "pattern":
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		p.pattern = pattern
+		return nil
+	case "arity":
+		return p.parseArity(value)
+	case "minlen", "maxlen":
+		length, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if key == "minlen" {
+			p.minLen = &length
+		} else {
+			p.maxLen = &length
+		}
+		return nil
+	case "count":
+		p.count = true
+		return nil
+	case "rune":
+		p.isRune = true
+		return nil
+	case "format":
+		p.format = value
+		return nil
+	case "file":
+		p.file = true
+		return nil
+	case "dir":
+		p.dir = true
+		return nil
+	}
+	return fmt.Errorf("unknown key %s", key)
+}
+
+// Sets the delimiters of array types if none is set.
+func (p *parameter) setDefaultDelimiters() {
+	if p.isDelimited() && p.delimiter == "" {
+		p.delimiter = constraintsDelimiter
+	}
+	if p.isMatrixType() && p.subDelimiter == "" {
+		p.subDelimiter = defaultSubDelimiter
+	}
+	// arrays are filled by repeated usages.
+	if p.isDelimited() && p.IsArrayType() {
+		p.repeatable = true
 	}
-	return fmt.Errorf("unknown key %s", splittedConstraint.value)
 }
 
 // Returns a new Parameter from the structField
@@ -321,24 +2220,23 @@ func newParameter(sf reflect.StructField) (*parameter, error) {
 		index: sf.Index[0],
 		tipe:  sf.Type,
 	}
-	if tag == "omit" {
+	if isOmitted(tag) {
 		return nil, nil
 	}
-	if newParam.IsArrayType() && newParam.delimiter == "" {
-		newParam.delimiter = constraintsDelimiter
-	}
 	if tag == "" {
+		newParam.setDefaultDelimiters()
 		return &newParam, nil
 	}
-	constraints := strings.Split(tag, constraintsDelimiter)
+	constraints := splitEscaped(tag, constraintsDelimiter)
 	for _, constraint := range constraints {
 		err := newParam.fillParameter(constraint)
 		if err != nil {
 			return nil, fmt.Errorf(
-				"error parsing constraint %s at field %s : %e",
+				"error parsing constraint %s at field %s : %s",
 				constraint, newParam.name, err)
 		}
 	}
+	newParam.setDefaultDelimiters()
 	if err := newParam.validate(); err != nil {
 		return nil, err
 	}
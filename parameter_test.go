@@ -1,9 +1,22 @@
 package yagclif
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,6 +42,32 @@ func TestSplitConstraint(t *testing.T) {
 		_, err := splitConstraint("hello:::")
 		assert.NotNil(t, err)
 	})
+	t.Run("With escaped delimiters", func(t *testing.T) {
+		kv, err := splitConstraint(`description:format\: host\:port\; or \d`)
+		assert.Nil(t, err)
+		assert.Equal(t, kv, keyValuePair{
+			key:   "description",
+			value: `format: host:port; or \d`,
+		})
+	})
+}
+
+func TestSplitEscaped(t *testing.T) {
+	assert.Equal(t, []string{"a", `b\;c`, ""}, splitEscaped(`a;b\;c;`, ";"))
+	assert.Equal(t, []string{""}, splitEscaped("", ";"))
+	t.Run("in tags", func(t *testing.T) {
+		type foo struct {
+			Addr  string    `yagclif:"description:format\\: host\\:port\\; default 80;default:localhost\\:80"`
+			Start time.Time `yagclif:"layout:15\\:04"`
+		}
+		param, err := newParameter(reflect.TypeOf(foo{}).Field(0))
+		assert.Nil(t, err)
+		assert.Equal(t, "format: host:port; default 80", param.description)
+		assert.Equal(t, "localhost:80", param.defaultValue)
+		param, err = newParameter(reflect.TypeOf(foo{}).Field(1))
+		assert.Nil(t, err)
+		assert.Equal(t, "15:04", param.getLayout())
+	})
 }
 
 func TestSplit(t *testing.T) {
@@ -37,6 +76,24 @@ func TestSplit(t *testing.T) {
 	}
 	splittedValues := p.Split("hello-world-!")
 	assert.Equal(t, []string{"hello", "world", "!"}, splittedValues)
+	splittedValues = p.Split(`hello\-world-\!-`)
+	assert.Equal(t, []string{"hello-world", `\!`, ""}, splittedValues)
+	t.Run("escaped elements", func(t *testing.T) {
+		type foo struct {
+			Paths  []string `yagclif:"delimiter:,"`
+			Matrix [][]int  `yagclif:"delimiter:/;subdelimiter:,"`
+		}
+		fooVar := &foo{}
+		values := []string{`a\,b,c`, `1,2/3`}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(foo{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(fooVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, &foo{Paths: []string{"a,b", "c"}, Matrix: [][]int{{1, 2}, {3}}}, fooVar)
+	})
 }
 func TestHasShortName(t *testing.T) {
 	t.Run("positive", func(t *testing.T) {
@@ -76,6 +133,63 @@ func TestMatches(t *testing.T) {
 			assert.True(t, param.Matches("--hello"))
 		})
 	})
+	t.Run("With cliName", func(t *testing.T) {
+		param := parameter{
+			name:    "OutputDir",
+			cliName: "output-dir",
+		}
+		assert.False(t, param.Matches("--outputdir"))
+		assert.True(t, param.Matches("--output-dir"))
+	})
+	t.Run("Negatable", func(t *testing.T) {
+		param := parameter{
+			name:      "Color",
+			negatable: true,
+		}
+		assert.Equal(t, []string{"--no-color"}, param.NegatedNames())
+		assert.True(t, param.Matches("--no-color"))
+		assert.True(t, param.IsNegation("--no-color"))
+		assert.False(t, param.IsNegation("--color"))
+		param.negatable = false
+		assert.False(t, param.Matches("--no-color"))
+	})
+	t.Run("Case sensitive", func(t *testing.T) {
+		param := parameter{
+			name:          "Define",
+			shortName:     "D",
+			caseSensitive: true,
+		}
+		assert.Equal(t, []string{"--Define", "-D"}, param.CliNames())
+		assert.False(t, param.Matches("-d"))
+		param.caseSensitive = false
+		assert.Equal(t, []string{"--define", "-d"}, param.CliNames())
+		defer func() { CaseSensitive, Matching = false, LowerCaseMatching }()
+		CaseSensitive = true
+		assert.Equal(t, []string{"--Define", "-D"}, param.CliNames())
+		// the deprecated setting is an alias of ExactCaseMatching.
+		Matching = CaseInsensitiveMatching
+		assert.False(t, param.Matches("--define"))
+		assert.True(t, param.Matches("--Define"))
+	})
+	t.Run("Positional", func(t *testing.T) {
+		param := parameter{
+			name:       "Src",
+			positional: true,
+		}
+		assert.Equal(t, []string{"SRC"}, param.CliNames())
+		assert.False(t, param.Matches("SRC"))
+		assert.False(t, param.Matches("--src"))
+	})
+	t.Run("With aliases", func(t *testing.T) {
+		param := parameter{
+			name:      "Color",
+			shortName: "c",
+			aliases:   []string{"colour", "tint"},
+		}
+		assert.Equal(t, []string{"--color", "--colour", "--tint", "-c"}, param.CliNames())
+		assert.True(t, param.Matches("--colour"))
+		assert.True(t, param.Matches("--tint"))
+	})
 }
 
 func TestGetValue(t *testing.T) {
@@ -91,6 +205,26 @@ func TestGetValue(t *testing.T) {
 	barValue.SetInt(int64(42))
 	assert.Equal(t, 42, fooVar.Bar)
 }
+
+// logLevel implements the Value interface.
+type logLevel int
+
+func (l *logLevel) String() string {
+	return []string{"info", "debug"}[*l]
+}
+
+func (l *logLevel) Set(s string) error {
+	switch s {
+	case "info":
+		*l = 0
+	case "debug":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown level %s", s)
+	}
+	return nil
+}
+
 func TestSetterCallBacks(t *testing.T) {
 	type foo struct {
 		Bar bool
@@ -137,6 +271,727 @@ func TestSetterCallBacks(t *testing.T) {
 			assert.NotNil(t, err)
 		})
 	})
+	t.Run("Set Int widths", func(t *testing.T) {
+		type bar struct {
+			I8  int8
+			I16 int16
+			I32 int32
+			I64 int64
+		}
+		barVar := &bar{}
+		overflows := []string{"128", "32768", "2147483648", "9223372036854775808"}
+		for i, overflow := range overflows {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack(overflow)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack("-42"))
+		}
+		assert.Equal(t, &bar{I8: -42, I16: -42, I32: -42, I64: -42}, barVar)
+	})
+	t.Run("Set Rune", func(t *testing.T) {
+		type bar struct {
+			CommentChar rune   `yagclif:"rune"`
+			Separators  []rune `yagclif:"rune;delimiter:,"`
+		}
+		barVar := &bar{}
+		values := []string{"#", "é,|"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("##")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, '#', barVar.CommentChar)
+		assert.Equal(t, []rune{'é', '|'}, barVar.Separators)
+	})
+	t.Run("Set named type", func(t *testing.T) {
+		type color string
+		type bar struct {
+			Color color
+		}
+		barVar := &bar{}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		assert.Nil(t, callBack("red"))
+		assert.Equal(t, color("red"), barVar.Color)
+	})
+	t.Run("Set Uint", func(t *testing.T) {
+		type bar struct {
+			U   uint
+			U64 uint64
+			U32 uint32
+		}
+		barVar := &bar{}
+		for i := 0; i < 3; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack("8080"))
+			err = callBack("-1")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "negative")
+		}
+		assert.Equal(t, &bar{U: 8080, U64: 8080, U32: 8080}, barVar)
+		t.Run("out of range", func(t *testing.T) {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(2))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.NotNil(t, callBack("4294967296"))
+		})
+	})
+	t.Run("Set Float", func(t *testing.T) {
+		type bar struct {
+			F64 float64
+			F32 float32
+		}
+		barVar := &bar{}
+		for i := 0; i < 2; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack("0.75"))
+			err = callBack("q")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+		}
+		assert.Equal(t, &bar{F64: 0.75, F32: 0.75}, barVar)
+	})
+	t.Run("Set Float Array", func(t *testing.T) {
+		type bar struct {
+			Weights []float64 `yagclif:"delimiter:,"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		barVar := &bar{}
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		err = callBack("0.1,q")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), param.name)
+		assert.Nil(t, callBack("0.1,0.2,0.7"))
+		assert.Equal(t, []float64{0.1, 0.2, 0.7}, barVar.Weights)
+	})
+	t.Run("Set Bool Array", func(t *testing.T) {
+		type bar struct {
+			Toggles []bool `yagclif:"delimiter:,"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		barVar := &bar{}
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		err = callBack("true,q")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), param.name)
+		assert.Nil(t, callBack("true,false,true"))
+		assert.Equal(t, []bool{true, false, true}, barVar.Toggles)
+	})
+	t.Run("Set Generic Array", func(t *testing.T) {
+		type bar struct {
+			Ports  []uint16    `yagclif:"delimiter:,"`
+			Colors []string    `yagclif:"delimiter:,;choices:red|green"`
+			Dates  []time.Time `yagclif:"delimiter:,;layout:2006-01-02"`
+		}
+		barVar := &bar{}
+		values := []string{"80,443", "red,green", "2024-01-01,2024-01-02"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			assert.True(t, param.IsArrayType())
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("-1,blue,q")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, []uint16{80, 443}, barVar.Ports)
+		assert.Equal(t, []string{"red", "green"}, barVar.Colors)
+		assert.Equal(t, 2, barVar.Dates[1].Day())
+	})
+	t.Run("Set Two Dimensional Array", func(t *testing.T) {
+		type bar struct {
+			Groups [][]string `yagclif:"delimiter:,;subdelimiter:|"`
+			Matrix [][]int
+			Cube   [][][]int `yagclif:"omit"`
+		}
+		barVar := &bar{}
+		values := []string{"a|b,c|d", "1,2;3,4"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			assert.True(t, param.isMatrixType())
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, barVar.Groups)
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}}, barVar.Matrix)
+		assert.False(t, isArray(reflect.TypeOf(bar{}).Field(2).Type))
+	})
+	t.Run("Set Duration", func(t *testing.T) {
+		type bar struct {
+			Timeout time.Duration
+			Backoff []time.Duration `yagclif:"delimiter:,"`
+		}
+		barVar := &bar{}
+		values := []string{"30s", "30s,1m"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.NotNil(t, callBack("q"))
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, &bar{
+			Timeout: 30 * time.Second,
+			Backoff: []time.Duration{30 * time.Second, time.Minute},
+		}, barVar)
+	})
+	t.Run("Set Time", func(t *testing.T) {
+		type bar struct {
+			Since time.Time `yagclif:"layout:2006-01-02"`
+			Until time.Time
+			At    time.Time `yagclif:"layout:Kitchen"`
+		}
+		values := []string{"2024-01-01", "2024-01-01T10:00:00Z", "3:04PM"}
+		expectedLayouts := []string{"2006-01-02", time.RFC3339, time.Kitchen}
+		barVar := &bar{}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			assert.Equal(t, expectedLayouts[i], param.getLayout())
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.NotNil(t, callBack("q"))
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), barVar.Since)
+		assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), barVar.Until)
+		assert.Equal(t, 15, barVar.At.Hour())
+	})
+	t.Run("Set JSON", func(t *testing.T) {
+		type payload struct {
+			Name string
+			Tags []string
+		}
+		type bar struct {
+			Payload payload           `yagclif:"format:json"`
+			Raw     json.RawMessage   `yagclif:"format:json"`
+			Map     map[string]string `yagclif:"format:json"`
+		}
+		barVar := &bar{}
+		values := []string{`{"Name":"foo","Tags":["a","b"]}`, `[1,2]`, `{"a":"b"}`}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			assert.False(t, param.isDelimited())
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("{")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, &bar{
+			Payload: payload{Name: "foo", Tags: []string{"a", "b"}},
+			Raw:     json.RawMessage(`[1,2]`),
+			Map:     map[string]string{"a": "b"},
+		}, barVar)
+	})
+	t.Run("Set Null", func(t *testing.T) {
+		type bar struct {
+			String sql.NullString
+			Int    sql.NullInt64
+			Bool   sql.NullBool
+			Time   sql.NullTime `yagclif:"layout:2006-01-02"`
+			Unset  sql.NullString
+		}
+		barVar := &bar{}
+		values := []string{"hello", "42", "false", "2024-01-01"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			if i > 0 {
+				err = callBack("q")
+				assert.NotNil(t, err)
+				assert.Contains(t, err.Error(), param.name)
+			}
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, sql.NullString{String: "hello", Valid: true}, barVar.String)
+		assert.Equal(t, sql.NullInt64{Int64: 42, Valid: true}, barVar.Int)
+		assert.Equal(t, sql.NullBool{Bool: false, Valid: true}, barVar.Bool)
+		assert.True(t, barVar.Time.Valid)
+		assert.Equal(t, 2024, barVar.Time.Time.Year())
+		assert.False(t, barVar.Unset.Valid)
+	})
+	t.Run("Set Location", func(t *testing.T) {
+		type bar struct {
+			Timezone *time.Location
+		}
+		barVar := &bar{}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		err = callBack("Nowhere/Atlantis")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), param.name)
+		assert.Nil(t, callBack("UTC"))
+		assert.Equal(t, time.UTC, barVar.Timezone)
+	})
+	t.Run("Set URL", func(t *testing.T) {
+		type bar struct {
+			Endpoint *url.URL `yagclif:"scheme:https"`
+			Proxy    url.URL
+		}
+		barVar := &bar{}
+		values := []string{"https://example.com/api", "http://proxy:3128"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("%zz")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, "example.com", barVar.Endpoint.Host)
+		assert.Equal(t, "proxy:3128", barVar.Proxy.Host)
+		t.Run("wrong scheme", func(t *testing.T) {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("http://example.com")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "scheme")
+		})
+	})
+	t.Run("Set Regexp", func(t *testing.T) {
+		type bar struct {
+			Match *regexp.Regexp
+		}
+		barVar := &bar{}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		err = callBack("^foo(")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), param.name)
+		assert.Contains(t, err.Error(), "^foo(")
+		assert.Nil(t, barVar.Match)
+		assert.Nil(t, callBack("^foo.*"))
+		assert.True(t, barVar.Match.MatchString("foobar"))
+		assert.False(t, barVar.Match.MatchString("barfoo"))
+	})
+	t.Run("Set Bytes", func(t *testing.T) {
+		type bar struct {
+			Raw    []byte
+			Base64 []byte `yagclif:"encoding:base64"`
+			Hex    []byte `yagclif:"encoding:hex"`
+		}
+		barVar := &bar{}
+		values := []string{"hello", "aGVsbG8=", "68656c6c6f"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			if param.encoding != "" {
+				err = callBack("!!")
+				assert.NotNil(t, err)
+				assert.Contains(t, err.Error(), param.name)
+			}
+			assert.Nil(t, callBack(value))
+		}
+		hello := []byte("hello")
+		assert.Equal(t, &bar{Raw: hello, Base64: hello, Hex: hello}, barVar)
+	})
+	t.Run("Set Choices", func(t *testing.T) {
+		type bar struct {
+			Color string `yagclif:"choices:red|green|blue"`
+			Level *int   `yagclif:"choices:1|2|3"`
+		}
+		barVar := &bar{}
+		values := []string{"green", "2"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("4")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), strings.Join(param.choices, ", "))
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, "green", barVar.Color)
+		assert.Equal(t, 2, *barVar.Level)
+	})
+	t.Run("Set Unit", func(t *testing.T) {
+		type bar struct {
+			TTL     int    `yagclif:"unit:seconds"`
+			Timeout *int64 `yagclif:"unit:ms"`
+			Limit   uint   `yagclif:"unit:bytes"`
+			Sizes   []int  `yagclif:"unit:KiB;delimiter:,"`
+			Small   int8   `yagclif:"unit:s"`
+		}
+		barVar := &bar{}
+		values := []string{"5m", "2s", "2k", "1MiB,4,2048B", "1h"}
+		for i, value := range values[:4] {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, 300, barVar.TTL)
+		assert.Equal(t, int64(2000), *barVar.Timeout)
+		assert.Equal(t, uint(2000), barVar.Limit)
+		assert.Equal(t, []int{1024, 4, 2}, barVar.Sizes)
+		errorValues := []string{"1500ms", "1x", "-1", "1000B", "1h"}
+		for i, value := range errorValues {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(&bar{})
+			assert.Nil(t, err)
+			assert.NotNil(t, callBack(value))
+		}
+	})
+	t.Run("Set Range", func(t *testing.T) {
+		type bar struct {
+			Port    int           `yagclif:"min:1;max:65535"`
+			Ratio   *float64      `yagclif:"max:1"`
+			Retries []uint        `yagclif:"delimiter:,;min:1"`
+			Limit   sql.NullInt64 `yagclif:"min:-10;max:10"`
+		}
+		barVar := &bar{}
+		values := []string{"0", "1.5", "1,0", "11"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack(value)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.rangeText())
+		}
+		values = []string{"8080", "0.5", "1,2", "-10"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, 8080, barVar.Port)
+		assert.Equal(t, 0.5, *barVar.Ratio)
+		assert.Equal(t, []uint{1, 2}, barVar.Retries)
+		assert.Equal(t, int64(-10), barVar.Limit.Int64)
+	})
+	t.Run("Set Length", func(t *testing.T) {
+		type bar struct {
+			Name *string  `yagclif:"minlen:2;maxlen:3"`
+			Tags []string `yagclif:"delimiter:,;minlen:1;maxlen:2"`
+			Key  []byte   `yagclif:"encoding:hex;maxlen:2"`
+		}
+		barVar := &bar{}
+		values := []string{"é", "a,b,c", "010203"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack(value)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "length")
+		}
+		values = []string{"été", "a", "0102"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, "été", *barVar.Name)
+		assert.Equal(t, []string{"a"}, barVar.Tags)
+		assert.Equal(t, []byte{1, 2}, barVar.Key)
+	})
+	t.Run("Set Pattern", func(t *testing.T) {
+		type bar struct {
+			Slug  string   `yagclif:"pattern:^[a-z0-9-]+$"`
+			Hosts []string `yagclif:"delimiter:,;pattern:^[a-z.]+$"`
+		}
+		barVar := &bar{}
+		values := []string{"Hello World", "example.com,127.0.0.1"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack(value)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.pattern.String())
+		}
+		values = []string{"hello-world", "example.com,localhost"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, "hello-world", barVar.Slug)
+		assert.Equal(t, []string{"example.com", "localhost"}, barVar.Hosts)
+	})
+	t.Run("Expand Env", func(t *testing.T) {
+		type bar struct {
+			LogDir  string   `yagclif:"expandenv;default:$BAR_HOME/logs"`
+			Paths   []string `yagclif:"expandenv;delimiter:,"`
+			Literal string
+		}
+		os.Setenv("BAR_HOME", "/home/bar")
+		defer os.Unsetenv("BAR_HOME")
+		barVar := &bar{}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		_, err = param.setDefault(barVar)
+		assert.Nil(t, err)
+		assert.Equal(t, "/home/bar/logs", barVar.LogDir)
+		values := []string{"${BAR_HOME}/var", "$BAR_HOME/a,b", "$BAR_HOME"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, &bar{
+			LogDir:  "/home/bar/var",
+			Paths:   []string{"/home/bar/a", "b"},
+			Literal: "$BAR_HOME",
+		}, barVar)
+	})
+	t.Run("Secret", func(t *testing.T) {
+		type bar struct {
+			Token  string `yagclif:"secret;pattern:^[a-f0-9]+$"`
+			Pin    int    `yagclif:"secret"`
+			Keys   []int  `yagclif:"secret;delimiter:,"`
+			Config string `yagclif:"secret;file"`
+		}
+		values := []string{"hunter2", "12ab", "1,hunter2", "/hunter2"}
+		for i, value := range values {
+			barVar := &bar{}
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack(value)
+			assert.NotNil(t, err)
+			assert.NotContains(t, err.Error(), "hunter2")
+			assert.NotContains(t, err.Error(), "12ab")
+			assert.Contains(t, err.Error(), secretMask)
+		}
+		barVar := &bar{}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(1))
+		assert.Nil(t, err)
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		err = callBack("e")
+		assert.NotNil(t, err)
+		assert.Equal(t, `parameter Pin : strconv.ParseInt: parsing "****": invalid syntax`, err.Error())
+	})
+	t.Run("Deprecated", func(t *testing.T) {
+		type bar struct {
+			Old     string `yagclif:"deprecated:use --new instead"`
+			Verbose bool   `yagclif:"deprecated"`
+		}
+		var output bytes.Buffer
+		defer func(writer io.Writer) { WarningOutput = writer }(WarningOutput)
+		WarningOutput = &output
+		barVar := &bar{}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		assert.Nil(t, callBack("hello"))
+		assert.Equal(t, "hello", barVar.Old)
+		assert.Equal(t, "warning: --old is deprecated, use --new instead\n", output.String())
+		output.Reset()
+		param, err = newParameter(reflect.TypeOf(bar{}).Field(1))
+		assert.Nil(t, err)
+		_, err = param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		assert.True(t, barVar.Verbose)
+		assert.Equal(t, "warning: --verbose is deprecated\n", output.String())
+	})
+	t.Run("Set Map", func(t *testing.T) {
+		type bar struct {
+			Label map[string]string `yagclif:"delimiter:,;default:env=dev"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		barVar := &bar{}
+		_, err = param.setDefault(barVar)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"env": "dev"}, barVar.Label)
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		assert.Nil(t, callBack("app=web"))
+		callBack, err = param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		assert.Nil(t, callBack("env=prod,tier=a=b"))
+		assert.Equal(t, map[string]string{
+			"app":  "web",
+			"env":  "prod",
+			"tier": "a=b",
+		}, barVar.Label)
+		t.Run("duplicate key", func(t *testing.T) {
+			err := callBack("app=api")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "duplicate")
+		})
+		t.Run("missing value", func(t *testing.T) {
+			assert.NotNil(t, callBack("novalue"))
+		})
+		t.Run("duplicates policies", func(t *testing.T) {
+			type baz struct {
+				First map[string]string `yagclif:"duplicates:first"`
+				Last  map[string]string `yagclif:"duplicates:last"`
+				Error map[string]string `yagclif:"duplicates:error"`
+			}
+			bazVar := &baz{}
+			for i := 0; i < 2; i++ {
+				param, err := newParameter(reflect.TypeOf(baz{}).Field(i))
+				assert.Nil(t, err)
+				for _, value := range []string{"a=1", "a=2;b=3"} {
+					callBack, err := param.SetterCallback(bazVar)
+					assert.Nil(t, err)
+					assert.Nil(t, callBack(value))
+				}
+			}
+			assert.Equal(t, map[string]string{"a": "1", "b": "3"}, bazVar.First)
+			assert.Equal(t, map[string]string{"a": "2", "b": "3"}, bazVar.Last)
+			param, err := newParameter(reflect.TypeOf(baz{}).Field(2))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(bazVar)
+			assert.Nil(t, err)
+			assert.NotNil(t, callBack("a=1;a=2"))
+		})
+	})
+	t.Run("Set Pointer", func(t *testing.T) {
+		type bar struct {
+			Int    *int
+			String *string
+			Bool   *bool
+			Unset  *int
+		}
+		barVar := &bar{}
+		values := []string{"42", "hello"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(2))
+		assert.Nil(t, err)
+		callBack, err := param.SetterCallback(barVar)
+		assert.Nil(t, err)
+		assert.Nil(t, callBack)
+		assert.Equal(t, 42, *barVar.Int)
+		assert.Equal(t, "hello", *barVar.String)
+		assert.True(t, *barVar.Bool)
+		assert.Nil(t, barVar.Unset)
+		t.Run("returns error", func(t *testing.T) {
+			barVar := &bar{}
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(3))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.NotNil(t, callBack("q"))
+			assert.Nil(t, barVar.Unset)
+		})
+	})
+	t.Run("Set Value", func(t *testing.T) {
+		type bar struct {
+			Level    logLevel
+			LevelPtr *logLevel
+		}
+		barVar := &bar{}
+		for i := 0; i < 2; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("loud")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack("debug"))
+		}
+		assert.Equal(t, logLevel(1), barVar.Level)
+		assert.Equal(t, logLevel(1), *barVar.LevelPtr)
+	})
+	t.Run("Set TextUnmarshaler", func(t *testing.T) {
+		type bar struct {
+			IP    net.IP
+			IPPtr *net.IP
+		}
+		barVar := &bar{}
+		for i := 0; i < 2; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("not an ip")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack("127.0.0.1"))
+		}
+		assert.Equal(t, "127.0.0.1", barVar.IP.String())
+		assert.Equal(t, "127.0.0.1", barVar.IPPtr.String())
+	})
+	t.Run("Set Big Numbers", func(t *testing.T) {
+		type bar struct {
+			Amount   *big.Int
+			Quantity big.Float
+		}
+		barVar := &bar{}
+		values := []string{"123456789012345678901234567890", "1.5e400"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack("q")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, "123456789012345678901234567890", barVar.Amount.String())
+		assert.Equal(t, "1.5e+400", barVar.Quantity.Text('g', 2))
+	})
 	t.Run("Set String Array", func(t *testing.T) {
 		field := reflect.TypeOf(foo{}).Field(3)
 		param, err := newParameter(field)
@@ -171,6 +1026,46 @@ func TestSetterCallBacks(t *testing.T) {
 			assert.NotNil(t, err)
 		})
 	})
+	t.Run("Set Count", func(t *testing.T) {
+		type bar struct {
+			Verbosity int `yagclif:"shortname:v;count"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.Nil(t, err)
+		barVar := &bar{}
+		for i := 0; i < 3; i++ {
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack)
+		}
+		assert.Equal(t, 3, barVar.Verbosity)
+	})
+	t.Run("Set Path", func(t *testing.T) {
+		type bar struct {
+			File string `yagclif:"file"`
+			Dir  string `yagclif:"dir"`
+		}
+		dir, err := ioutil.TempDir("", "yagclif")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "file")
+		assert.Nil(t, ioutil.WriteFile(file, []byte{}, 0600))
+		values := []string{file, dir}
+		barVar := &bar{}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(barVar)
+			assert.Nil(t, err)
+			err = callBack(filepath.Join(dir, "missing"))
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), param.name)
+			// the file is not a directory and vice versa.
+			assert.NotNil(t, callBack(values[1-i]))
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, &bar{File: file, Dir: dir}, barVar)
+	})
 	t.Run("checks for multiple usages", func(t *testing.T) {
 		field := reflect.TypeOf(foo{}).Field(5)
 		param, err := newParameter(field)
@@ -257,6 +1152,29 @@ func TestNewParameter(t *testing.T) {
 			assert.Equal(t, param.delimiter, "!")
 		})
 	})
+	t.Run("name", func(t *testing.T) {
+		type foo struct {
+			OutputDir string `yagclif:"name:output-dir"`
+			Empty     string `yagclif:"name:"`
+		}
+		param, err := newParameter(reflect.TypeOf(foo{}).Field(0))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--output-dir"}, param.CliNames())
+		assert.Equal(t, "OutputDir", param.name)
+		_, err = newParameter(reflect.TypeOf(foo{}).Field(1))
+		assert.NotNil(t, err)
+	})
+	t.Run("aliases", func(t *testing.T) {
+		type foo struct {
+			Color string `yagclif:"aliases:colour|tint"`
+			Empty string `yagclif:"aliases:colour|"`
+		}
+		param, err := newParameter(reflect.TypeOf(foo{}).Field(0))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"colour", "tint"}, param.aliases)
+		_, err = newParameter(reflect.TypeOf(foo{}).Field(1))
+		assert.NotNil(t, err)
+	})
 	t.Run("Returns error", func(t *testing.T) {
 		field := reflect.TypeOf(foo{}).Field(0)
 		_, err := newParameter(field)
@@ -309,6 +1227,170 @@ func TestParamHelp(t *testing.T) {
 		help := param.GetHelp()
 		stringContains(help, "--bar", "string", ":", "some int", "mandatory")
 	})
+	t.Run("byte size type", func(t *testing.T) {
+		param := parameter{
+			name: "Bar",
+			tipe: reflect.TypeOf(ByteSize(0)),
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "size")
+		stringDoesnotContain(help, "yagclif.ByteSize")
+	})
+	t.Run("choices", func(t *testing.T) {
+		param := parameter{
+			name:    "Bar",
+			tipe:    reflect.TypeOf(""),
+			choices: []string{"red", "green"},
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "choices", "red|green")
+	})
+	t.Run("range", func(t *testing.T) {
+		min, max := 1.0, 2.5
+		param := parameter{
+			name: "Bar",
+			tipe: reflect.TypeOf(0.0),
+			min:  &min,
+			max:  &max,
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "min 1", "max 2.5")
+	})
+	t.Run("secret", func(t *testing.T) {
+		param := parameter{
+			name:         "Bar",
+			tipe:         reflect.TypeOf(""),
+			defaultValue: "hunter2",
+			secret:       true,
+		}
+		help := param.GetHelp()
+		stringContains(help, "(default: ****)")
+		stringDoesnotContain(help, "hunter2")
+	})
+	t.Run("implicit", func(t *testing.T) {
+		param := parameter{
+			name:          "Bar",
+			tipe:          reflect.TypeOf(""),
+			implicitValue: "dev",
+		}
+		help := param.GetHelp()
+		stringContains(help, "(value optional, implicit: dev)")
+	})
+	t.Run("example", func(t *testing.T) {
+		param := parameter{
+			name:        "Bar",
+			tipe:        reflect.TypeOf(map[string]string{}),
+			delimiter:   ",",
+			example:     "--bar env=prod,tier=web",
+			description: "labels",
+		}
+		help := param.GetHelp()
+		stringContains(help, "(example: --bar env=prod,tier=web) : labels")
+	})
+	t.Run("placeholder", func(t *testing.T) {
+		param := parameter{
+			name:        "Bar",
+			tipe:        reflect.TypeOf([]string{}),
+			delimiter:   ",",
+			placeholder: "FILES",
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar FILES delimiter ,")
+		stringDoesnotContain(help, "[]string")
+	})
+	t.Run("requires", func(t *testing.T) {
+		param := parameter{
+			name:     "Bar",
+			tipe:     reflect.TypeOf(""),
+			requires: []string{"foo", "db-host"},
+		}
+		help := param.GetHelp()
+		stringContains(help, "(requires --foo --db-host)")
+	})
+	t.Run("oneof", func(t *testing.T) {
+		param := parameter{
+			name:  "Bar",
+			tipe:  reflect.TypeOf(""),
+			oneOf: "input",
+		}
+		help := param.GetHelp()
+		stringContains(help, "(one of input)")
+	})
+	t.Run("xor", func(t *testing.T) {
+		param := parameter{
+			name: "Bar",
+			tipe: reflect.TypeOf(""),
+			xor:  "source",
+		}
+		help := param.GetHelp()
+		stringContains(help, "(exactly one of source)")
+	})
+	t.Run("conflicts", func(t *testing.T) {
+		param := parameter{
+			name:      "Bar",
+			tipe:      reflect.TypeOf(true),
+			conflicts: []string{"foo"},
+		}
+		help := param.GetHelp()
+		stringContains(help, "(conflicts with --foo)")
+	})
+	t.Run("negatable", func(t *testing.T) {
+		param := parameter{
+			name:      "Bar",
+			tipe:      reflect.TypeOf(true),
+			negatable: true,
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar --no-bar bool")
+	})
+	t.Run("deprecated", func(t *testing.T) {
+		param := parameter{
+			name:        "Bar",
+			tipe:        reflect.TypeOf(""),
+			deprecated:  true,
+			deprecation: "use --foo instead",
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "(DEPRECATED: use --foo instead)")
+	})
+	t.Run("pattern", func(t *testing.T) {
+		param := parameter{
+			name:    "Bar",
+			tipe:    reflect.TypeOf(""),
+			pattern: regexp.MustCompile("^[a-z]+$"),
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "pattern ^[a-z]+$")
+	})
+	t.Run("length", func(t *testing.T) {
+		minLen, maxLen := 1, 3
+		param := parameter{
+			name:   "Bar",
+			tipe:   reflect.TypeOf(""),
+			minLen: &minLen,
+			maxLen: &maxLen,
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "minlen 1", "maxlen 3")
+	})
+	t.Run("environment variable", func(t *testing.T) {
+		param := parameter{
+			name: "Bar",
+			tipe: reflect.TypeOf(""),
+			env:  "BAR",
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "(env: BAR)")
+	})
+	t.Run("default value", func(t *testing.T) {
+		param := parameter{
+			name:         "Bar",
+			tipe:         reflect.TypeOf(""),
+			defaultValue: "foo",
+		}
+		help := param.GetHelp()
+		stringContains(help, "--bar", "(default: foo)")
+	})
 	t.Run("string array ", func(t *testing.T) {
 		param := parameter{
 			name:      "Bar",
@@ -333,6 +1415,17 @@ func TestParamHelp(t *testing.T) {
 		help := param.GetHelp()
 		stringContains(help, "--bar", "[]int", "delimiter", "whitespace", "some int array", "mandatory")
 	})
+	t.Run("unknown flags", func(t *testing.T) {
+		type foo struct {
+			Extra []string `yagclif:"unknown;description:forwarded flags"`
+		}
+		param, err := newParameter(reflect.TypeOf(foo{}).Field(0))
+		assert.Nil(t, err)
+		help := param.GetHelp()
+		stringContains(help, "forwarded flags")
+		assert.NotContains(t, help, "[]string")
+		assert.NotContains(t, help, "delimiter")
+	})
 }
 
 func TestValidate(t *testing.T) {
@@ -359,6 +1452,205 @@ func TestValidate(t *testing.T) {
 		assert.NotNil(t, err)
 		assert.Nil(t, param)
 	})
+	t.Run("error on mandatory bool pointer", func(t *testing.T) {
+		type bar struct {
+			Bar *bool `yagclif:"mandatory"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on choices for non string or int type", func(t *testing.T) {
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"choices:true|false"`
+		param, err := newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on range", func(t *testing.T) {
+		type bar struct {
+			Name   string `yagclif:"min:1"`
+			Port   int    `yagclif:"min:10;max:1"`
+			Size   int    `yagclif:"max:ten"`
+			Offset int    `yagclif:"max:10;default:11"`
+		}
+		for i := 0; i < 4; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("error on positional", func(t *testing.T) {
+		type bar struct {
+			Verbose bool   `yagclif:"positional"`
+			Count   int    `yagclif:"positional;count"`
+			Src     string `yagclif:"positional;shortname:s"`
+			Dst     string `yagclif:"positional:first"`
+			Rest    string `yagclif:"positional:rest"`
+		}
+		for i := 0; i < 5; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("error on unit", func(t *testing.T) {
+		type bar struct {
+			Name    string        `yagclif:"unit:s"`
+			Ratio   float64       `yagclif:"unit:s"`
+			Timeout time.Duration `yagclif:"unit:s"`
+			TTL     int           `yagclif:"unit:days"`
+			Size    ByteSize      `yagclif:"unit:KB"`
+		}
+		for i := 0; i < 5; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("error on implicit", func(t *testing.T) {
+		type bar struct {
+			Verbose bool `yagclif:"implicit:true"`
+			Level   int  `yagclif:"implicit:high"`
+			Count   int  `yagclif:"implicit:1;count"`
+		}
+		for i := 0; i < 3; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("error on unknown key", func(t *testing.T) {
+		type bar struct {
+			Bar string `yagclif:"colour:red"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.EqualError(t, err, "error parsing constraint colour:red at field Bar : unknown key colour")
+		assert.Nil(t, param)
+	})
+	t.Run("error on repeatable for non array type", func(t *testing.T) {
+		type bar struct {
+			Bar string `yagclif:"repeatable"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on duplicates", func(t *testing.T) {
+		type bar struct {
+			Tags   []string          `yagclif:"duplicates:last"`
+			Labels map[string]string `yagclif:"duplicates:random"`
+		}
+		for i := 0; i < 2; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("error on negatable for non bool type", func(t *testing.T) {
+		type bar struct {
+			Bar string `yagclif:"negatable"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on pattern", func(t *testing.T) {
+		type bar struct {
+			Port int    `yagclif:"pattern:^[0-9]+$"`
+			Name string `yagclif:"pattern:[a-z"`
+			Slug string `yagclif:"pattern:^[a-z]+$;default:Foo"`
+		}
+		for i := 0; i < 3; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("error on length", func(t *testing.T) {
+		type bar struct {
+			Port int      `yagclif:"minlen:1"`
+			Name string   `yagclif:"minlen:3;maxlen:1"`
+			Tags []string `yagclif:"maxlen:two"`
+			Dir  string   `yagclif:"maxlen:2;default:tmp"`
+		}
+		for i := 0; i < 4; i++ {
+			param, err := newParameter(reflect.TypeOf(bar{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("error on default not in choices", func(t *testing.T) {
+		type bar struct {
+			Color string `yagclif:"choices:red|green;default:blue"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on subdelimiter for non two dimensional array", func(t *testing.T) {
+		type bar struct {
+			Bar []string `yagclif:"subdelimiter:|"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on rune for non rune type", func(t *testing.T) {
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"rune"`
+		param, err := newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on unknown format", func(t *testing.T) {
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"format:yaml"`
+		param, err := newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on file for non string type", func(t *testing.T) {
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"file"`
+		param, err := newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on count for non int type", func(t *testing.T) {
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"count"`
+		param, err := newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on encoding", func(t *testing.T) {
+		type bar struct {
+			Secret []byte `yagclif:"encoding:rot13"`
+		}
+		param, err := newParameter(reflect.TypeOf(bar{}).Field(0))
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"encoding:hex"`
+		param, err = newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on scheme for non url type", func(t *testing.T) {
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"scheme:https"`
+		param, err := newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+	t.Run("error on layout for non time type", func(t *testing.T) {
+		field := reflect.TypeOf(foo{}).Field(0)
+		field.Tag = `yagclif:"layout:2006-01-02"`
+		param, err := newParameter(field)
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
 	t.Run("error array with empty delimiter", func(t *testing.T) {
 		field := reflect.TypeOf(foo{}).Field(0)
 		field.Tag = `yagclif:"delimiter:-"`
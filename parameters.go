@@ -1,10 +1,19 @@
 package yagclif
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/potatomasterrace/catch"
 )
@@ -12,22 +21,79 @@ import (
 type parameters []*parameter
 
 func isSupportedType(sf reflect.StructField) bool {
+	return isSupported(sf.Type)
+}
+
+// Returns if the type can be parsed, pointers to
+// non array and non map types are supported.
+func isSupported(tipe reflect.Type) bool {
+	if tipe == reflect.TypeOf(&time.Location{}) {
+		return true
+	}
+	if tipe.Kind() == reflect.Ptr {
+		elemKind := tipe.Elem().Kind()
+		customType := isValue(tipe.Elem()) || isTextUnmarshaler(tipe.Elem())
+		if (elemKind == reflect.Slice || elemKind == reflect.Map) && !customType {
+			return false
+		}
+		return isSupported(tipe.Elem())
+	}
+	switch tipe.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	if isArray(tipe) || isNullType(tipe) {
+		return true
+	}
 	supportedTypes := []reflect.Type{
-		reflect.TypeOf(true),
-		reflect.TypeOf(1), reflect.TypeOf(""),
-		reflect.TypeOf([]string{}),
-		reflect.TypeOf([]int{}),
+		reflect.TypeOf(time.Duration(0)),
+		reflect.TypeOf(time.Time{}),
+		reflect.TypeOf(url.URL{}),
+		reflect.TypeOf(regexp.Regexp{}),
+		reflect.TypeOf([]byte{}),
+		reflect.TypeOf(map[string]string{}),
 	}
 	for _, supportedType := range supportedTypes {
-		if supportedType == sf.Type {
+		if supportedType == tipe {
 			return true
 		}
 	}
-	return false
+	return isValue(tipe) || isTextUnmarshaler(tipe)
 }
 
 // Returns the parameters from an object tags.
 func newParameters(tipe reflect.Type) (parameters, error) {
+	return newParametersWithOptions(tipe, defaultOptions())
+}
+
+// Returns the parameters from an object tags
+// parsed with the behavior of the options.
+func newParametersWithOptions(tipe reflect.Type, options *parseOptions) (parameters, error) {
+	params, err := newNestedParameters(tipe, nil, options)
+	if err != nil {
+		return nil, err
+	}
+	if err = params.checkReferences(); err != nil {
+		return nil, err
+	}
+	if err = params.checkPositionals(); err != nil {
+		return nil, err
+	}
+	if err = params.checkUnknown(); err != nil {
+		return nil, err
+	}
+	if err = params.checkPOSIX(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// Returns the parameters from the tags of an object
+// nested in the parents struct fields.
+func newNestedParameters(tipe reflect.Type, parents []reflect.StructField, options *parseOptions) (parameters, error) {
 	params := parameters{}
 	err := catch.Error(func() {
 		tipe.NumField()
@@ -41,10 +107,25 @@ func newParameters(tipe reflect.Type) (parameters, error) {
 		if err != nil {
 			return nil, err
 		}
-		if param != nil && isSupportedType(field) {
+		if param != nil {
+			param.parseOptions = options
+		}
+		if param != nil && param.command {
+			if param.commandParams, err = newParametersWithOptions(param.valueType(), options); err != nil {
+				return nil, fmt.Errorf("%s\r\n error parsing command field %s  ", err, field.Name)
+			}
+			param.parents = parents
+			params = append(params, param)
+		} else if param != nil && (isSupportedType(field) || param.isJSON()) {
+			param.parents = parents
 			params = append(params, param)
-		} else if field.Tag.Get(tagName) != "omit" {
-			inheritedParams, err := newParameters(field.Type)
+		} else if !isOmitted(field.Tag.Get(tagName)) {
+			fieldParents := append(append([]reflect.StructField{}, parents...), field)
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct {
+				fieldType = fieldType.Elem()
+			}
+			inheritedParams, err := newNestedParameters(fieldType, fieldParents, options)
 			if err != nil {
 				return nil, fmt.Errorf("%s\r\n error parsing recursively field %s  ", err, field.Name)
 			}
@@ -62,7 +143,7 @@ func newParameters(tipe reflect.Type) (parameters, error) {
 func (params *parameters) checkValidity() error {
 	existingNames := make(map[string]*parameter, 0)
 	for _, param := range *params {
-		for _, name := range param.CliNames() {
+		for _, name := range append(param.CliNames(), param.NegatedNames()...) {
 			conflictingParam := existingNames[name]
 			if conflictingParam != nil {
 				return fmt.Errorf(
@@ -76,9 +157,152 @@ func (params *parameters) checkValidity() error {
 	return nil
 }
 
+// Validates that the parameters referenced
+// by constraints exist.
+func (params *parameters) checkReferences() error {
+	for _, param := range *params {
+		for _, name := range param.requires {
+			if params.findByName(name) == nil {
+				return fmt.Errorf(
+					"parameter %s : requires unknown parameter %s",
+					param.name, name,
+				)
+			}
+		}
+		for _, name := range param.conflicts {
+			if params.findByName(name) == nil {
+				return fmt.Errorf(
+					"parameter %s : conflicts with unknown parameter %s",
+					param.name, name,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// Validates that no positional parameter
+// follows a rest positional parameter.
+func (params *parameters) checkPositionals() error {
+	var rest *parameter
+	orders := map[int]*parameter{}
+	for _, param := range params.positionals() {
+		if param.order != nil && orders[*param.order] != nil {
+			return fmt.Errorf(
+				"parameter %s : positional order %d already used by %s",
+				param.name, *param.order, orders[*param.order].name,
+			)
+		} else if param.order != nil {
+			orders[*param.order] = param
+		}
+		if rest != nil {
+			return fmt.Errorf(
+				"parameter %s : positional after rest positional %s",
+				param.name, rest.name,
+			)
+		}
+		if param.rest {
+			rest = param
+		}
+	}
+	return nil
+}
+
+// Validates that every flag has a single character
+// shortname and no negation in POSIX mode.
+func (params *parameters) checkPOSIX() error {
+	if !params.options().posix {
+		return nil
+	}
+	for _, param := range *params {
+		if param.command && param.interspersed != nil && *param.interspersed {
+			return fmt.Errorf("parameter %s : interspersed command in POSIX mode", param.name)
+		}
+		if param.positional || param.command || param.unknown {
+			continue
+		}
+		if utf8.RuneCountInString(param.shortName) != 1 || param.negatable {
+			return fmt.Errorf(
+				"parameter %s : POSIX mode requires a single character shortname and no negation",
+				param.name,
+			)
+		}
+	}
+	return nil
+}
+
+// Validates that at most one parameter
+// collects the unknown flags.
+func (params *parameters) checkUnknown() error {
+	var collector *parameter
+	for _, param := range *params {
+		if collector != nil && param.unknown {
+			return fmt.Errorf(
+				"parameter %s : unknown flags already collected by %s",
+				param.name, collector.name,
+			)
+		} else if param.unknown {
+			collector = param
+		}
+	}
+	return nil
+}
+
+// Returns the parameter collecting the unknown flags.
+func (params *parameters) unknownCollector() *parameter {
+	for _, param := range *params {
+		if param.unknown {
+			return param
+		}
+	}
+	return nil
+}
+
+// Finds a parameter by its struct field name
+// or by the name used in constraints.
+func (params *parameters) findField(name string) *parameter {
+	for _, param := range *params {
+		if param.name == name {
+			return param
+		}
+	}
+	return params.findByName(name)
+}
+
+// Adds the callback to the parameters of the field and to
+// the ones of the commands, returns if the field is found.
+func (params *parameters) addCallback(name string, callback FieldCallback) bool {
+	found := false
+	if param := params.findField(name); param != nil {
+		param.callbacks = append(param.callbacks, callback)
+		found = true
+	}
+	for _, command := range params.commands() {
+		found = command.commandParams.addCallback(name, callback) || found
+	}
+	return found
+}
+
+// Finds a parameter in the array by the name used
+// in constraints, case sensitive names are found first.
+func (params *parameters) findByName(name string) *parameter {
+	for _, cliName := range []string{name, strings.ToLower(name), strings.ToLower(params.options().naming(name))} {
+		if param := params.find(fmt.Sprint(namePrefix, cliName)); param != nil {
+			return param
+		}
+	}
+	return nil
+}
+
 // Finds a parameter in the array by cli names :
-// -name or --shortname.
+// -name or --shortname, the exact names are
+// found before the ones differing by case.
 func (params *parameters) find(s string) *parameter {
+	for _, param := range *params {
+		if param.Matches(s) && param.hasName(s) {
+			return param
+		}
+	}
 	for _, param := range *params {
 		if param.Matches(s) {
 			return param
@@ -87,89 +311,957 @@ func (params *parameters) find(s string) *parameter {
 	return nil
 }
 
-// Returns an array describing the parameters.
+// Value prefixed to the help of grouped parameters.
+const groupIndent = "  "
+
+// Returns an array describing the parameters,
+// hidden parameters are skipped. Grouped parameters
+// are shown after the others under their group name
+// in the order the groups first appear.
 func (params *parameters) getHelp() []string {
 	var buffer []string
+	visibleParams := parameters{}
 	for _, param := range *params {
-		buffer = append(buffer, param.GetHelp())
+		if param.hidden || param.command {
+			continue
+		}
+		visibleParams = append(visibleParams, param)
+		if param.group == "" {
+			buffer = append(buffer, param.GetHelp())
+		}
+	}
+	names, groups := visibleParams.groupBy(func(param *parameter) string {
+		return param.group
+	})
+	for _, name := range names {
+		buffer = append(buffer, name+":")
+		for _, param := range groups[name] {
+			buffer = append(buffer, groupIndent+param.GetHelp())
+		}
 	}
-	return buffer
+	return append(buffer, params.commandsHelp()...)
 }
 
+// Sets the default values of the tags, then the ones of
+// SetDefaults and then the environment variables.
 func (params *parameters) assignDefaults(obj interface{}) error {
 	for _, param := range *params {
-		_, err := param.setDefault(obj)
-		if err != nil {
+		if _, found := param.lookupEnv(); found {
+			continue
+		}
+		if _, err := param.setTagDefault(obj); err != nil {
 			return err
 		}
 	}
+	for _, nested := range params.nestedStructs(obj) {
+		if defaulter, ok := nested.(Defaulter); ok {
+			defaulter.SetDefaults()
+		}
+	}
+	for _, param := range *params {
+		if envValue, found := param.lookupEnv(); found {
+			if err := param.setEnv(obj, envValue); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+// Defaulter is implemented by the structs, nested ones
+// included, and commands setting their default values
+// programmatically, they override the default constraints
+// but not the environment variables and the arguments.
+type Defaulter interface {
+	SetDefaults()
+}
+
 func (params *parameters) checkForMissingMandatory() error {
+	errs := Errors{}
 	for _, param := range *params {
-		if param.mandatory && !param.used {
+		if param.mandatory && !param.used && !param.setByEnv {
 			if param.description != "" {
-				return fmt.Errorf("missing argument %s for %s %s", param.CliNames(), param.name, param.description)
+				errs.add(fmt.Errorf("missing argument %s for %s %s", param.CliNames(), param.name, param.description))
+			} else {
+				errs.add(fmt.Errorf("missing argument %s for %s", param.CliNames(), param.name))
+			}
+		}
+	}
+	return errs.err()
+}
+
+// Asks the confirmation of the used parameters
+// with the confirm constraint.
+func (params *parameters) confirmUsed(input *bufio.Reader) error {
+	for _, param := range *params {
+		if param.confirm != "" && param.used {
+			if err := param.askConfirmation(input); err != nil {
+				return err
 			}
-			return fmt.Errorf("missing argument %s for %s", param.CliNames(), param.name)
 		}
 	}
 	return nil
 }
 
-// Fills the object with the argument.
-// This function only works if the obj
-// value is not nil.
-func (params *parameters) ParseArguments(obj interface{}, args []string) ([]string, error) {
-	params.assignDefaults(obj)
+// Prompts the values of the parameters with the
+// prompt constraint not found in the arguments.
+func (params *parameters) promptMissing(obj interface{}, input *bufio.Reader) error {
+	for _, param := range *params {
+		if param.prompt && !param.used && !param.setByEnv {
+			if err := param.promptValue(obj, input); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the positional parameters by order, the
+// positional parameters without order follow
+// the others in the order of the struct fields.
+func (params *parameters) positionals() parameters {
+	positionals := parameters{}
+	for _, param := range *params {
+		if param.positional {
+			positionals = append(positionals, param)
+		}
+	}
+	sort.SliceStable(positionals, func(i, j int) bool {
+		first, second := positionals[i].order, positionals[j].order
+		return first != nil && (second == nil || *first < *second)
+	})
+	return positionals
+}
+
+// Returns the usage line of the program : the names of its
+// positional parameters by order, optional ones between
+// brackets, followed by [flags] and the commands.
+func (params *parameters) usageLine(program string) string {
+	words := []string{program}
+	for _, param := range params.positionals() {
+		if param.hidden {
+			continue
+		}
+		if param.mandatory || (param.minArity != nil && *param.minArity > 0) {
+			words = append(words, param.CliNames()[0])
+		} else {
+			words = append(words, "["+param.CliNames()[0]+"]")
+		}
+	}
+	hasFlags, hasCommands, mandatoryCommand := false, false, false
+	for _, param := range *params {
+		if param.command && !param.hidden {
+			hasCommands = true
+			mandatoryCommand = mandatoryCommand || param.mandatory
+		} else if !param.positional && !param.unknown && !param.hidden {
+			hasFlags = true
+		}
+	}
+	if hasFlags {
+		words = append(words, "[flags]")
+	}
+	if mandatoryCommand {
+		words = append(words, "COMMAND")
+	} else if hasCommands {
+		words = append(words, "[COMMAND]")
+	}
+	return strings.Join(words, " ")
+}
+
+// Validates that the parameters required by
+// the used parameters are set.
+func (params *parameters) checkRequirements() error {
+	for _, param := range *params {
+		if !param.used {
+			continue
+		}
+		for _, name := range param.requires {
+			required := params.findByName(name)
+			if !required.used && !required.setByEnv {
+				return fmt.Errorf("%s requires %s",
+					param.CliNames()[0], required.CliNames()[0],
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the names of the groups in the order they first
+// appear and the parameters of each group by name.
+func (params *parameters) groupBy(groupOf func(param *parameter) string) ([]string, map[string]parameters) {
+	names := []string{}
+	groups := map[string]parameters{}
+	for _, param := range *params {
+		name := groupOf(param)
+		if name == "" {
+			continue
+		}
+		if _, found := groups[name]; !found {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], param)
+	}
+	return names, groups
+}
+
+// Returns the first Cli names of the parameters.
+func (params *parameters) cliNames() []string {
+	names := []string{}
+	for _, param := range *params {
+		names = append(names, param.CliNames()[0])
+	}
+	return names
+}
+
+// Validates the number of arguments and the
+// length of the rest positional parameters.
+func (params *parameters) checkArities(obj interface{}) error {
+	for _, param := range *params {
+		if err := param.checkArity(obj); err != nil {
+			return err
+		}
+		if err := param.checkRestLength(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validates that at least one parameter of
+// each oneof group is set.
+func (params *parameters) checkOneOfGroups() error {
+	names, groups := params.groupBy(func(param *parameter) string {
+		return param.oneOf
+	})
+	for _, name := range names {
+		found := false
+		for _, param := range groups[name] {
+			found = found || param.used || param.setByEnv
+		}
+		if !found {
+			members := groups[name]
+			return fmt.Errorf("missing one of %s for %s",
+				strings.Join(members.cliNames(), " "), name,
+			)
+		}
+	}
+	return nil
+}
+
+// Validates that exactly one parameter of each xor group
+// is set, parameters set by their environment variables
+// are only counted when no parameter of the group is used.
+func (params *parameters) checkXorGroups() error {
+	names, groups := params.groupBy(func(param *parameter) string {
+		return param.xor
+	})
+	for _, name := range names {
+		members := groups[name]
+		used, setByEnv := 0, 0
+		for _, param := range members {
+			if param.used {
+				used++
+			} else if param.setByEnv {
+				setByEnv++
+			}
+		}
+		if used == 0 {
+			used = setByEnv
+		}
+		if used == 0 {
+			return fmt.Errorf("missing one of %s for %s",
+				strings.Join(members.cliNames(), " "), name,
+			)
+		} else if used > 1 {
+			return fmt.Errorf("only one of %s can be used for %s",
+				strings.Join(members.cliNames(), " "), name,
+			)
+		}
+	}
+	return nil
+}
+
+// Validates that no used parameter conflicts
+// with another used parameter.
+func (params *parameters) checkConflicts() error {
+	for _, param := range *params {
+		if !param.used {
+			continue
+		}
+		for _, name := range param.conflicts {
+			conflicting := params.findByName(name)
+			if conflicting.used {
+				return fmt.Errorf("%s can not be used with %s",
+					param.CliNames()[0], conflicting.CliNames()[0],
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// Argument ending the flags, the following
+// arguments are positional or remaining arguments.
+const flagsTerminator = "--"
+
+// Value between the name and the value
+// of an argument such as --name=value.
+const flagValueDelimiter = "="
+
+// Returns if the argument not matching any parameter is
+// a flag, a single hyphen and negative numbers are operands.
+func isUnknownFlag(arg string) bool {
+	return strings.HasPrefix(arg, shortNamePrefix) && arg != shortNamePrefix && !isNumber(arg)
+}
+
+// Returns if the argument following the unknown flag at the index
+// is its value : the flag has no = sign, the argument is neither
+// a flag, a command nor the terminator, and the positional
+// parameters left get enough operands without it.
+func (params *parameters) takesUnknownValue(args []string, i int, positionals parameters) bool {
+	next := i + 1
+	if strings.Contains(args[i], flagValueDelimiter) || next == len(args) {
+		return false
+	}
+	arg, commands := args[next], params.commands()
+	if arg == flagsTerminator || isUnknownFlag(arg) || params.isFlag(arg) || commands.findCommand(arg) != nil {
+		return false
+	}
+	needed := 0
+	for _, positional := range positionals {
+		if !positional.used {
+			needed++
+		}
+	}
+	operands := 0
+	for j := next + 1; j < len(args) && operands < needed; j++ {
+		if args[j] == flagsTerminator {
+			operands += len(args) - j - 1
+			break
+		}
+		if commands.findCommand(args[j]) != nil {
+			break
+		}
+		param, _, _, hasValue := params.findFlag(args[j])
+		// the values of the known flags are not operands.
+		if param != nil && !hasValue && !param.isBoolType() && !param.count && param.implicitValue == "" {
+			j++
+		} else if param == nil && !isUnknownFlag(args[j]) {
+			operands++
+		}
+	}
+	return operands >= needed
+}
+
+// Returns if the argument following a bool flag is its value,
+// other values must follow an = sign.
+func isBoolLiteral(arg string) bool {
+	return strings.EqualFold(arg, "true") || strings.EqualFold(arg, "false")
+}
+
+// Returns if the argument is a number such as -5 or -0.5.
+func isNumber(arg string) bool {
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
+// Finds the parameter and the name of a flag argument, the
+// value of --name=value and -shortname=value arguments
+// is split from the name.
+func (params *parameters) findFlag(arg string) (param *parameter, name string, value string, hasValue bool) {
+	options := params.options()
+	if options.windowsFlags && !options.posix && strings.HasPrefix(arg, windowsFlagPrefix) {
+		return params.findWindowsFlag(arg)
+	}
+	if param := params.find(arg); param != nil {
+		return param, arg, "", false
+	}
+	if !strings.HasPrefix(arg, shortNamePrefix) {
+		return nil, "", "", false
+	}
+	parts := strings.SplitN(arg, flagValueDelimiter, 2)
+	if len(parts) != 2 {
+		return params.findShortSyntaxFlag(arg)
+	}
+	if param := params.find(parts[0]); param != nil {
+		return param, parts[0], parts[1], true
+	}
+	return params.findShortSyntaxFlag(arg)
+}
+
+// Finds the parameter of an argument using the single
+// dash long names or the glued short values syntaxes.
+func (params *parameters) findShortSyntaxFlag(arg string) (param *parameter, name string, value string, hasValue bool) {
+	if param, name, value, hasValue := params.findSingleDashFlag(arg); param != nil {
+		return param, name, value, hasValue
+	}
+	return params.findGluedFlag(arg)
+}
+
+// Splits the first shortname of the argument when it is a
+// bool or count flag, -vn5 is split into -v and -n5.
+func (params *parameters) splitGroupedFlags(arg string) (first string, rest string, found bool) {
+	if strings.HasPrefix(arg, namePrefix) || !strings.HasPrefix(arg, shortNamePrefix) {
+		return "", "", false
+	}
+	runes := []rune(strings.TrimPrefix(arg, shortNamePrefix))
+	if len(runes) < 2 {
+		return "", "", false
+	}
+	first = shortNamePrefix + string(runes[0])
+	if param := params.find(first); param != nil && (param.isBoolType() || param.count) {
+		return first, shortNamePrefix + string(runes[1:]), true
+	}
+	return "", "", false
+}
+
+// Finds the parameter of which the shortname starts the
+// argument, -n5 is -n 5. The longest shortname of
+// the parameters taking a value is matched.
+func (params *parameters) findGluedFlag(arg string) (param *parameter, name string, value string, hasValue bool) {
+	if strings.HasPrefix(arg, namePrefix) || !strings.HasPrefix(arg, shortNamePrefix) {
+		return nil, "", "", false
+	}
+	exact := false
+	for _, candidate := range *params {
+		if !candidate.hasShortName() || candidate.isBoolType() || candidate.count {
+			continue
+		}
+		shortName := shortNamePrefix + candidate.cased(candidate.shortName)
+		if len(arg) <= len(shortName) || !candidate.matchesName(shortName, arg[:len(shortName)]) {
+			continue
+		}
+		// exact shortnames are matched before the ones differing by case.
+		candidateExact := shortName == arg[:len(shortName)]
+		if len(shortName) > len(name) || (len(shortName) == len(name) && candidateExact && !exact) {
+			param, name, exact = candidate, shortName, candidateExact
+		}
+	}
+	if param == nil {
+		return nil, "", "", false
+	}
+	return param, name, arg[len(name):], true
+}
+
+// Finds the parameter of a -name or -name=value argument
+// when SingleDashLongNames is true, the name is
+// returned as its --name equivalent.
+func (params *parameters) findSingleDashFlag(arg string) (param *parameter, name string, value string, hasValue bool) {
+	options := params.options()
+	if !options.singleDashLongNames || options.posix || strings.HasPrefix(arg, namePrefix) {
+		return nil, "", "", false
+	}
+	return params.findFlag(shortNamePrefix + arg)
+}
+
+// Returns if the argument is a flag of a parameter,
+// ambiguous abbreviations included.
+func (params *parameters) isFlag(arg string) bool {
+	param, _, _, _ := params.findFlag(arg)
+	if param != nil {
+		return true
+	}
+	param, _, _, _, err := params.findAbbreviation(arg)
+	return param != nil || err != nil
+}
+
+// Finds the parameter of which a long name starts with the
+// argument when Abbreviations is true, --verb matches --verbose.
+// The error lists the candidates when several parameters match.
+func (params *parameters) findAbbreviation(arg string) (param *parameter, name string, value string, hasValue bool, err error) {
+	options := params.options()
+	if (!options.abbreviations && !options.gnu) || !strings.HasPrefix(arg, namePrefix) || arg == flagsTerminator {
+		return nil, "", "", false, nil
+	}
+	parts := strings.SplitN(arg, flagValueDelimiter, 2)
+	candidates := []string{}
+	for _, candidate := range *params {
+		for _, candidateName := range append(candidate.CliNames(), candidate.NegatedNames()...) {
+			if strings.HasPrefix(candidateName, parts[0]) && candidate.Matches(candidateName) {
+				if param != candidate {
+					candidates = append(candidates, candidateName)
+				}
+				param, name = candidate, candidateName
+				break
+			}
+		}
+	}
+	if len(candidates) > 1 {
+		return nil, "", "", false, fmt.Errorf(
+			"ambiguous flag %s could be %s",
+			parts[0], strings.Join(candidates, " "),
+		)
+	} else if param != nil && len(parts) == 2 {
+		return param, name, parts[1], true, nil
+	}
+	return param, name, "", false, nil
+}
+
+// Prefix of the flags in the windows syntax.
+const windowsFlagPrefix = "/"
+
+// Value between the name and the value of
+// a flag in the windows syntax.
+const windowsValueDelimiter = ":"
+
+// Finds the parameter of a /name or /name:value argument,
+// the name is returned as its --name or -name equivalent.
+func (params *parameters) findWindowsFlag(arg string) (param *parameter, name string, value string, hasValue bool) {
+	parts := strings.SplitN(strings.TrimPrefix(arg, windowsFlagPrefix), windowsValueDelimiter, 2)
+	for _, prefix := range []string{namePrefix, shortNamePrefix} {
+		name = prefix + parts[0]
+		if param := params.find(name); param != nil && len(parts) == 2 {
+			return param, name, parts[1], true
+		} else if param != nil {
+			return param, name, "", false
+		}
+	}
+	return nil, "", "", false
+}
+
+// Settings of a parsing that commands can change.
+type parseSettings struct {
+	// Context passed to the hooks of the parameters.
+	ctx context.Context
+	// Writer of the warnings, WarningOutput if nil.
+	warnings io.Writer
+	// If true unknown flags are returned
+	// as they are with the remaining arguments.
+	keepUnknown bool
+	// If false the flags end at the first operand.
+	interspersed bool
+	// Number of leading arguments coming from
+	// the ArgsEnv environment variable.
+	envArgs int
+}
+
+// Returns the settings of a parsing from the package options.
+func newParseSettings(ctx context.Context, keepUnknown bool) parseSettings {
+	return parseSettings{
+		ctx:          ctx,
+		keepUnknown:  keepUnknown,
+		interspersed: defaultOptions().interspersedFlags(),
+	}
+}
+
+// Sets the parameters found in the arguments and returns
+// the arguments that are neither flags nor values, the
+// parsing goes on after errors to report them together.
+func (params *parameters) consumeArguments(obj interface{}, args []string, settings parseSettings) ([]string, error) {
 	remainingArgs := []string{}
-	var callback func(string) error
-	for _, arg := range args {
-		param := params.find(arg)
-		if callback == nil {
-			if param != nil {
-				var err error
-				callback, err = param.SetterCallback(obj)
-				if err != nil {
-					return nil, err
+	positionals := params.positionals()
+	commands := params.commands()
+	collector := params.unknownCollector()
+	errs := Errors{}
+	options := params.options()
+	envArgs := settings.envArgs
+	// the flags of the ArgsEnv arguments keep the value they
+	// replace, the command line flags replace them again.
+	replaceEnvArgs := func(param *parameter, i int) {
+		if i < envArgs && !param.used {
+			param.fromEnvArgs, param.beforeEnvArgs = true, copyValue(param.getValue(obj))
+		} else if i >= envArgs && param.fromEnvArgs {
+			param.getValue(obj).Set(param.beforeEnvArgs)
+			param.used, param.fromEnvArgs = false, false
+		}
+	}
+	// adds the error of setting the parameter or
+	// calls its callbacks with the raw value.
+	set := func(param *parameter, raw string, err error) {
+		if err != nil {
+			errs.add(err)
+			return
+		}
+		for _, callback := range param.callbacks {
+			errs.add(callback(settings.ctx, raw, param.getValue(obj).Interface()))
+		}
+	}
+	// fills the next positional parameter or
+	// adds the argument to the remaining ones.
+	addOperand := func(arg string) {
+		if len(positionals) == 0 {
+			remainingArgs = append(remainingArgs, arg)
+			return
+		}
+		positional := positionals[0]
+		// rest positional parameters take all the arguments left.
+		if positional.rest {
+			set(positional, arg, positional.appendValue(obj, arg))
+			return
+		}
+		positionals = positionals[1:]
+		setter, err := positional.SetterCallback(obj)
+		if err != nil {
+			errs.add(err)
+			return
+		}
+		set(positional, arg, setter(arg))
+	}
+	addOperands := func(operands []string) {
+		for _, operand := range operands {
+			addOperand(operand)
+		}
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// arguments after the terminator are not flags.
+		if arg == flagsTerminator {
+			addOperands(args[i+1:])
+			break
+		}
+		// the arguments after a command are its arguments.
+		if command := commands.findCommand(arg); command != nil {
+			settings.envArgs = 0
+			if envArgs > i {
+				settings.envArgs = envArgs - i - 1
+			}
+			commandArgs, err := command.runCommand(obj, args[i+1:], settings)
+			errs.add(err)
+			remainingArgs = append(remainingArgs, commandArgs...)
+			break
+		}
+		// POSIX has no long names.
+		if options.posix && strings.HasPrefix(arg, namePrefix) {
+			errs.add(fmt.Errorf("long option %s can not be used in POSIX mode", arg))
+			continue
+		}
+		param, name, value, hasValue := params.findFlag(arg)
+		// grouped short flags are split, -vn5 is -v -n5.
+		if param == nil && (options.posix || options.gnu) {
+			if first, rest, found := params.splitGroupedFlags(arg); found {
+				args = append(append(args[:i:i], first, rest), args[i+1:]...)
+				if i < envArgs {
+					envArgs++
 				}
-			} else {
-				remainingArgs = append(remainingArgs, arg)
+				i--
+				continue
 			}
-		} else {
-			err := callback(arg)
+		}
+		if param == nil {
+			var err error
+			param, name, value, hasValue, err = params.findAbbreviation(arg)
 			if err != nil {
-				return nil, err
+				errs.add(err)
+				continue
 			}
-			callback = nil
+		}
+		// unknown flags are collected with their
+		// values instead of being operands.
+		if param == nil && collector != nil && isUnknownFlag(arg) {
+			set(collector, arg, collector.appendValue(obj, arg))
+			if params.takesUnknownValue(args, i, positionals) {
+				i++
+				set(collector, args[i], collector.appendValue(obj, args[i]))
+			}
+			continue
+		}
+		// unknown flags and their values are left to a later parsing.
+		if param == nil && settings.keepUnknown && isUnknownFlag(arg) {
+			remainingArgs = append(remainingArgs, arg)
+			if params.takesUnknownValue(args, i, positionals) {
+				i++
+				remainingArgs = append(remainingArgs, args[i])
+			}
+			continue
+		}
+		// unknown arguments are returned with the following ones.
+		if param == nil && options.stopAtUnknown && (len(positionals) == 0 || isUnknownFlag(arg)) {
+			remainingArgs = append(remainingArgs, args[i:]...)
+			break
+		}
+		// without interspersed flags the flags end at the first operand.
+		if param == nil && !settings.interspersed {
+			addOperands(args[i:])
+			break
+		} else if param == nil {
+			addOperand(arg)
+			continue
+		}
+		replaceEnvArgs(param, i)
+		if param.IsNegation(name) && hasValue {
+			errs.add(fmt.Errorf("%s does not take a value", name))
+			continue
+		} else if param.IsNegation(name) {
+			set(param, "", param.Negate(obj))
+			continue
+		}
+		setter, err := param.SetterCallback(obj)
+		if err != nil {
+			errs.add(err)
+			// the value of the flag is skipped.
+			if !hasValue && !param.isBoolType() && i+1 < len(args) && !params.isFlag(args[i+1]) {
+				i++
+			}
+			continue
+		}
+		// counts have no value, bools have an optional value.
+		if setter == nil && hasValue && !param.isBoolType() {
+			errs.add(fmt.Errorf("%s does not take a value", param.CliNames()[0]))
+			continue
+		} else if setter == nil && param.isBoolType() {
+			if !hasValue && i+1 < len(args) && isBoolLiteral(args[i+1]) {
+				value, hasValue = args[i+1], true
+				i++
+			}
+			if hasValue {
+				set(param, value, param.elemSetterOnValue(param.getValue(obj))(value))
+			} else {
+				set(param, "", nil)
+			}
+			continue
+		} else if setter == nil {
+			set(param, "", nil)
+			continue
+		}
+		if !hasValue {
+			next := i + 1
+			// optional values are implicit when followed by a flag,
+			// or always in GNU mode where they follow an = sign.
+			if param.implicitValue != "" && (options.gnu || next == len(args) || isUnknownFlag(args[next]) || params.isFlag(args[next])) {
+				value = param.implicitValue
+			} else if next == len(args) {
+				errs.add(fmt.Errorf("missing value for %s", param.CliNames()[0]))
+				continue
+			} else {
+				value = args[next]
+				i = next
+			}
+		}
+		set(param, value, setter(value))
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return remainingArgs, nil
+}
+
+// Fills the object with the argument.
+// This function only works if the obj
+// value is not nil.
+func (params *parameters) ParseArguments(obj interface{}, args []string) ([]string, error) {
+	_, remainingArgs, err := params.parseSession(obj, args, newParseSettings(context.Background(), false))
+	return remainingArgs, err
+}
+
+// Fills the object with the arguments using the settings once
+// the response files are expanded, the state of the parsing
+// is kept by the returned session instead of the parameters.
+func (params *parameters) parseSession(obj interface{}, args []string, settings parseSettings) (parameters, []string, error) {
+	if params.options().responseFiles {
+		envArgs, err := expandResponseFiles(args[:settings.envArgs])
+		if err != nil {
+			return nil, nil, err
+		}
+		args, err = expandResponseFiles(args[settings.envArgs:])
+		if err != nil {
+			return nil, nil, err
+		}
+		settings.envArgs = len(envArgs)
+		args = append(envArgs, args...)
+	}
+	session := params.newSession()
+	remainingArgs, err := session.parseArguments(obj, args, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, remainingArgs, nil
+}
+
+// Returns copies of the parameters holding the state of
+// one parsing, the parameters themselves are never modified
+// so that they can be parsed again or concurrently.
+func (params *parameters) newSession() parameters {
+	session := make(parameters, len(*params))
+	for i, param := range *params {
+		copied := *param
+		copied.commandParams = param.commandParams.newSession()
+		session[i] = &copied
+	}
+	return session
+}
+
+// Fills the object with the argument using the settings,
+// the problems of the arguments are reported together.
+func (params *parameters) parseArguments(obj interface{}, args []string, settings parseSettings) ([]string, error) {
+	if err := settings.ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, param := range *params {
+		param.settings = &settings
+	}
+	if err := params.assignDefaults(obj); err != nil {
+		return nil, err
+	}
+	remainingArgs, err := params.consumeArguments(obj, args, settings)
+	errs := Errors{}
+	errs.add(err)
+	// nothing is prompted for wrong arguments.
+	if err == nil {
+		input := bufio.NewReader(PromptInput)
+		if err := params.confirmUsed(input); err != nil {
+			return nil, err
+		}
+		if err := params.promptMissing(obj, input); err != nil {
+			return nil, err
+		}
+	}
+	errs.add(params.checkForMissingMandatory())
+	errs.add(params.checkArities(obj))
+	errs.add(params.checkOneOfGroups())
+	errs.add(params.checkXorGroups())
+	errs.add(params.checkRequirements())
+	errs.add(params.checkConflicts())
+	// the rules of the structs are checked on valid fields.
+	for _, nested := range params.nestedStructs(obj) {
+		if validatable, ok := nested.(Validatable); ok && len(errs) == 0 {
+			errs.add(validatable.Validate())
 		}
 	}
-	if err := params.checkForMissingMandatory(); err != nil {
+	if err := errs.err(); err != nil {
 		return nil, err
 	}
 	return remainingArgs, nil
 }
 
+// Validatable is implemented by the structs, nested ones
+// included, and commands checking their own rules once
+// their fields are set.
+type Validatable interface {
+	Validate() error
+}
+
+// Returns pointers to the nested structs holding parameters,
+// the deepest ones first, followed by the object itself.
+// Nil struct pointers are skipped.
+func (params *parameters) nestedStructs(obj interface{}) []interface{} {
+	type nestedStruct struct {
+		value interface{}
+		depth int
+	}
+	nested, seen := []nestedStruct{}, map[string]bool{}
+	for _, param := range *params {
+		objValue, path := reflect.ValueOf(obj).Elem(), ""
+		for depth, parent := range param.parents {
+			objValue = objValue.Field(parent.Index[0])
+			if objValue.Kind() == reflect.Ptr && objValue.IsNil() {
+				break
+			} else if objValue.Kind() == reflect.Ptr {
+				objValue = objValue.Elem()
+			}
+			path += "." + parent.Name
+			if !seen[path] {
+				seen[path] = true
+				nested = append(nested, nestedStruct{objValue.Addr().Interface(), depth})
+			}
+		}
+	}
+	sort.SliceStable(nested, func(i, j int) bool {
+		return nested[i].depth > nested[j].depth
+	})
+	structs := []interface{}{}
+	for _, nestedStruct := range nested {
+		structs = append(structs, nestedStruct.value)
+	}
+	return append(structs, obj)
+}
+
 func Parse(obj interface{}) (remainingArgs []string, err error) {
+	return NewParser().Parse(obj)
+}
+
+// ParseCommand fills the object like Parse and returns the names
+// of the selected commands from the top level one to the leaf one.
+func ParseCommand(obj interface{}) (commands []string, remainingArgs []string, err error) {
+	return NewParser().ParseCommand(obj)
+}
+
+// ParseContext fills the object like Parse, the validators,
+// default functions and prompts get the context.
+func ParseContext(ctx context.Context, obj interface{}) (remainingArgs []string, err error) {
+	return NewParser().ParseContext(ctx, obj)
+}
+
+// MustParse fills the object like Parse, on errors they are written
+// with the help to os.Stderr before exiting with ExitCode.
+func MustParse(obj interface{}) (remainingArgs []string) {
+	return NewParser().MustParse(obj)
+}
+
+// ParseArgs fills the object like Parse with the arguments instead
+// of the ones of the program, they do not start with its name.
+func ParseArgs(obj interface{}, args []string) (remainingArgs []string, err error) {
+	return NewParser().ParseArgs(obj, args)
+}
+
+// Returns the arguments of the ArgsEnv environment
+// variable followed by the arguments and the number
+// of arguments coming from the variable.
+func withEnvArgs(args []string, argsEnv string) ([]string, int, error) {
+	if argsEnv == "" {
+		return args, 0, nil
+	}
+	envArgs, err := tokenize(os.Getenv(argsEnv))
+	if err != nil {
+		return nil, 0, fmt.Errorf("environment variable %s : %s", argsEnv, err)
+	}
+	return append(envArgs, args...), len(envArgs), nil
+}
+
+// ParseKnown fills the object with the arguments matching its
+// parameters and returns the others, unknown flags included
+// in their order, so that they can be parsed into another object.
+func ParseKnown(obj interface{}, args []string) (remainingArgs []string, err error) {
 	tipe := reflect.TypeOf(obj).Elem()
 	params, err := newParameters(tipe)
 	if err != nil {
 		return nil, err
 	}
-	remainingArgs, err = params.ParseArguments(obj, os.Args[1:])
+	_, remainingArgs, err = params.parseSession(obj, args, newParseSettings(context.Background(), true))
 	if err != nil {
-		return nil, fmt.Errorf(
-			"%s\r\nusage:\r\n%s\r\n",
-			err, strings.Join(
-				params.getHelp(),
-				"\r\n",
-			),
-		)
+		return nil, params.usageError(err, programName())
 	}
 	return remainingArgs, nil
 }
 
+// ParseString fills the object like Parse with the arguments
+// of the command line split like a shell does, the command
+// line does not start with the name of the program.
+func ParseString(obj interface{}, commandLine string) (remainingArgs []string, err error) {
+	args, err := tokenize(commandLine)
+	if err != nil {
+		return nil, err
+	}
+	tipe := reflect.TypeOf(obj).Elem()
+	params, err := newParameters(tipe)
+	if err != nil {
+		return nil, err
+	}
+	remainingArgs, err = params.ParseArguments(obj, args)
+	if err != nil {
+		return nil, params.usageError(err, programName())
+	}
+	return remainingArgs, nil
+}
+
+// Returns the error followed by the usage line
+// and the help of the parameters.
+func (params *parameters) usageError(err error, program string) error {
+	return fmt.Errorf(
+		"%w\r\nusage:\r\n%s\r\n",
+		err, strings.Join(
+			params.getUsage(program),
+			"\r\n",
+		),
+	)
+}
+
+// Returns the usage line of the program
+// followed by the help of the parameters.
+func (params *parameters) getUsage(program string) []string {
+	return append([]string{params.usageLine(program)}, params.getHelp()...)
+}
+
 func GetHelp(obj interface{}) string {
 	tipe := reflect.TypeOf(obj).Elem()
 	params, err := newParameters(tipe)
@@ -177,7 +1269,7 @@ func GetHelp(obj interface{}) string {
 		return fmt.Sprintf("%s", err)
 	}
 	return strings.Join(
-		params.getHelp(),
+		params.getUsage(programName()),
 		"\r\n",
 	)
 
@@ -1,9 +1,18 @@
 package yagclif
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -37,6 +46,53 @@ func TestNewParameters(t *testing.T) {
 		assert.False(t, params[2].mandatory)
 		assert.Equal(t, 2, params[2].index)
 	})
+	t.Run("skips - fields", func(t *testing.T) {
+		type foo struct {
+			Name   string
+			Logger *log.Logger `yagclif:"-"`
+			Hash   string      `yagclif:"-"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(params))
+		assert.Equal(t, "Name", params[0].name)
+	})
+	t.Run("does not recurse into time.Time", func(t *testing.T) {
+		type foo struct {
+			Since    time.Time
+			Timezone *time.Location
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(params))
+		assert.Equal(t, "Since", params[0].name)
+	})
+	t.Run("does not recurse into json fields", func(t *testing.T) {
+		type foo struct {
+			Payload struct {
+				Name string
+			} `yagclif:"format:json"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(params))
+		assert.Equal(t, []string{"--payload"}, params[0].CliNames())
+	})
+	t.Run("supports pointers", func(t *testing.T) {
+		type foo struct {
+			Port    *int
+			Verbose *bool
+			IP      *net.IP
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		fooInstance := &foo{}
+		_, err = params.ParseArguments(fooInstance, []string{"--port", "80"})
+		assert.Nil(t, err)
+		assert.Equal(t, 80, *fooInstance.Port)
+		assert.Nil(t, fooInstance.Verbose)
+		assert.Nil(t, fooInstance.IP)
+	})
 	t.Run("returns error", func(t *testing.T) {
 		t.Run("new parameters error", func(t *testing.T) {
 			params, err := newParameters(faultyStructType)
@@ -78,6 +134,142 @@ func TestNewParametersInheritance(t *testing.T) {
 	})
 }
 
+func TestNewParametersNested(t *testing.T) {
+	type dbConfig struct {
+		Host string
+		Port int
+	}
+	type foo struct {
+		DB      dbConfig
+		Replica struct {
+			DB dbConfig
+		}
+	}
+	params, err := newParameters(reflect.TypeOf(foo{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(params))
+	assert.Equal(t, []string{"--db-host"}, params[0].CliNames())
+	assert.Equal(t, []string{"--replica-db-port"}, params[3].CliNames())
+	t.Run("parses", func(t *testing.T) {
+		fooInstance := &foo{}
+		remaining, err := params.ParseArguments(fooInstance, []string{"--db-host", "localhost", "--replica-db-port", "5432"})
+		assert.Nil(t, err)
+		assert.Empty(t, remaining)
+		assert.Equal(t, "localhost", fooInstance.DB.Host)
+		assert.Equal(t, 5432, fooInstance.Replica.DB.Port)
+	})
+	t.Run("conflicting shortnames", func(t *testing.T) {
+		type shortNamed struct {
+			Host string `yagclif:"shortname:h"`
+		}
+		type bar struct {
+			A shortNamed
+			B shortNamed
+		}
+		params, err := newParameters(reflect.TypeOf(bar{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+	})
+	t.Run("names", func(t *testing.T) {
+		type output struct {
+			Dir string `yagclif:"name:directory"`
+		}
+		type bar struct {
+			Output output
+			Dir    string `yagclif:"name:output-dir"`
+		}
+		params, err := newParameters(reflect.TypeOf(bar{}))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--output-directory"}, params[0].CliNames())
+		barInstance := &bar{}
+		_, err = params.ParseArguments(barInstance, []string{"--output-directory", "a", "--output-dir", "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, "a", barInstance.Output.Dir)
+		assert.Equal(t, "b", barInstance.Dir)
+	})
+	t.Run("conflicting aliases", func(t *testing.T) {
+		type output struct {
+			Dir string `yagclif:"aliases:directory"`
+		}
+		type bar struct {
+			Output output
+			Dir    string `yagclif:"aliases:output-dir"`
+		}
+		params, err := newParameters(reflect.TypeOf(bar{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+		assert.Contains(t, err.Error(), "--output-dir")
+	})
+}
+
+func TestNewParametersReferences(t *testing.T) {
+	type db struct {
+		Password string `yagclif:"requires:user|db-host"`
+	}
+	type foo struct {
+		User string
+		DB   struct {
+			Host string
+			db
+		}
+	}
+	params, err := newParameters(reflect.TypeOf(foo{}))
+	assert.Nil(t, err)
+	assert.Len(t, params, 3)
+	t.Run("unknown parameter", func(t *testing.T) {
+		type bar struct {
+			Password string `yagclif:"requires:username"`
+		}
+		params, err := newParameters(reflect.TypeOf(bar{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+	})
+	t.Run("unknown conflicting parameter", func(t *testing.T) {
+		type bar struct {
+			Verbose bool `yagclif:"conflicts:quiet"`
+		}
+		params, err := newParameters(reflect.TypeOf(bar{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+	})
+}
+
+func TestNewParametersEmbedded(t *testing.T) {
+	type CommonFlags struct {
+		Verbose bool
+		Config  string `yagclif:"default:config.yml"`
+	}
+	type Foo struct {
+		*CommonFlags
+		Port int
+	}
+	type Bar struct {
+		CommonFlags
+		Host string
+	}
+	t.Run("pointer", func(t *testing.T) {
+		params, err := newParameters(reflect.TypeOf(Foo{}))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(params))
+		assert.Equal(t, []string{"--verbose"}, params[0].CliNames())
+		fooInstance := &Foo{}
+		_, err = params.ParseArguments(fooInstance, []string{"--verbose"})
+		assert.Nil(t, err)
+		assert.NotNil(t, fooInstance.CommonFlags)
+		assert.True(t, fooInstance.Verbose)
+		assert.Equal(t, "config.yml", fooInstance.Config)
+	})
+	t.Run("value", func(t *testing.T) {
+		params, err := newParameters(reflect.TypeOf(Bar{}))
+		assert.Nil(t, err)
+		barInstance := &Bar{}
+		_, err = params.ParseArguments(barInstance, []string{"--config", "other.yml", "--host", "localhost"})
+		assert.Nil(t, err)
+		assert.Equal(t, "other.yml", barInstance.Config)
+		assert.Equal(t, "localhost", barInstance.Host)
+	})
+}
+
 func TestFind(t *testing.T) {
 	params, err := newParameters(validStructType)
 	assert.Nil(t, err)
@@ -96,6 +288,39 @@ func TestParamsGetHelp(t *testing.T) {
 	assert.Nil(t, err)
 	help := params.getHelp()
 	assert.Len(t, help, 3)
+	t.Run("groups", func(t *testing.T) {
+		type foo struct {
+			Host    string `yagclif:"group:Networking"`
+			Verbose bool
+			Output  string `yagclif:"group:Output"`
+			Port    int    `yagclif:"group:Networking"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"--verbose bool ",
+			"Networking:",
+			"  --host string ",
+			"  --port int ",
+			"Output:",
+			"  --output string ",
+		}, params.getHelp())
+	})
+	t.Run("skips hidden", func(t *testing.T) {
+		type foo struct {
+			Name  string
+			Debug bool `yagclif:"hidden"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		help := params.getHelp()
+		assert.Len(t, help, 1)
+		assert.NotContains(t, help[0], "--debug")
+		fooInstance := &foo{}
+		_, err = params.ParseArguments(fooInstance, []string{"--debug"})
+		assert.Nil(t, err)
+		assert.True(t, fooInstance.Debug)
+	})
 }
 func TestAssignDefault(t *testing.T) {
 	t.Run("works", func(t *testing.T) {
@@ -162,6 +387,966 @@ func TestParseArguments(t *testing.T) {
 			C: true,
 		}, testStruct)
 	})
+	t.Run("negations", func(t *testing.T) {
+		type foo struct {
+			Color   bool  `yagclif:"negatable;env:FOO_COLOR"`
+			Cache   *bool `yagclif:"negatable"`
+			Verbose bool  `yagclif:"negatable"`
+		}
+		os.Setenv("FOO_COLOR", "true")
+		defer os.Unsetenv("FOO_COLOR")
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"--no-color", "--no-cache", "--verbose", "--no-foo"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--no-foo"}, remaining)
+		assert.False(t, testStruct.Color)
+		assert.NotNil(t, testStruct.Cache)
+		assert.False(t, *testStruct.Cache)
+		assert.True(t, testStruct.Verbose)
+		_, err = params.ParseArguments(&foo{}, []string{"--verbose", "--no-verbose"})
+		assert.NotNil(t, err)
+	})
+	t.Run("requirements", func(t *testing.T) {
+		type foo struct {
+			Username string `yagclif:"env:FOO_USERNAME"`
+			Password string `yagclif:"requires:username"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--password", "secret"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "--password")
+		assert.Contains(t, err.Error(), "--username")
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--password", "secret", "--username", "me"})
+		assert.Nil(t, err)
+		os.Setenv("FOO_USERNAME", "me")
+		defer os.Unsetenv("FOO_USERNAME")
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--password", "secret"})
+		assert.Nil(t, err)
+	})
+	t.Run("case sensitive", func(t *testing.T) {
+		type foo struct {
+			Exclude  bool `yagclif:"shortname:X;casesensitive"`
+			Execute  bool `yagclif:"shortname:x"`
+			Username string
+			Password string `yagclif:"requires:Username"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"-X", "--Execute"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--Execute"}, remaining)
+		assert.Equal(t, &foo{Exclude: true}, testStruct)
+	})
+	t.Run("matching policies", func(t *testing.T) {
+		defer func() { Matching = LowerCaseMatching }()
+		type foo struct {
+			MaxRetries int
+			Execute    bool `yagclif:"shortname:x"`
+			Exclude    bool `yagclif:"shortname:X;casesensitive"`
+			Verbose    bool `yagclif:"negatable;default:true"`
+			Lines      int  `yagclif:"shortname:n"`
+			Number     int  `yagclif:"shortname:N;casesensitive"`
+		}
+		cases := []struct {
+			matching  MatchingPolicy
+			args      []string
+			expected  foo
+			remaining []string
+		}{
+			{LowerCaseMatching, []string{"--maxretries", "1", "--MaxRetries", "2"}, foo{MaxRetries: 1, Verbose: true}, []string{"--MaxRetries", "2"}},
+			{ExactCaseMatching, []string{"--maxretries", "1", "--MaxRetries", "2"}, foo{MaxRetries: 2, Verbose: true}, []string{"--maxretries", "1"}},
+			{CaseInsensitiveMatching, []string{"--MAXRETRIES", "3", "-x", "-X"}, foo{MaxRetries: 3, Execute: true, Exclude: true, Verbose: true}, []string{}},
+			{CaseInsensitiveMatching, []string{"--NO-VERBOSE"}, foo{}, []string{}},
+			{CaseInsensitiveMatching, []string{"-X", "-N5"}, foo{Exclude: true, Verbose: true, Number: 5}, []string{}},
+		}
+		for _, c := range cases {
+			Matching = c.matching
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Equal(t, c.remaining, remaining)
+			assert.Equal(t, &c.expected, testStruct)
+		}
+	})
+	t.Run("prompts", func(t *testing.T) {
+		type foo struct {
+			Name    string `yagclif:"prompt;mandatory;description:your name"`
+			Port    int    `yagclif:"prompt;default:8080"`
+			Verbose bool   `yagclif:"prompt"`
+			Color   string `yagclif:"prompt"`
+		}
+		defer func(input io.Reader, output io.Writer) {
+			PromptInput, PromptOutput = input, output
+		}(PromptInput, PromptOutput)
+		var output bytes.Buffer
+		PromptInput, PromptOutput = strings.NewReader("me\r\n\ntrue\n"), &output
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--color", "red"})
+		assert.Nil(t, err)
+		assert.Equal(t, &foo{Name: "me", Port: 8080, Verbose: true, Color: "red"}, testStruct)
+		assert.Equal(t, "--name (your name): --port [8080]: --verbose: ", output.String())
+		PromptInput = strings.NewReader("\n")
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "missing argument")
+		PromptInput = strings.NewReader("me\nhttp\n")
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{})
+		assert.NotNil(t, err)
+	})
+	t.Run("confirmations", func(t *testing.T) {
+		type foo struct {
+			Purge bool `yagclif:"confirm:This deletes all data, continue?"`
+			Name  string
+		}
+		defer func(input io.Reader, output io.Writer) {
+			PromptInput, PromptOutput = input, output
+		}(PromptInput, PromptOutput)
+		var output bytes.Buffer
+		PromptInput, PromptOutput = strings.NewReader("Yes\n"), &output
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--purge"})
+		assert.Nil(t, err)
+		assert.True(t, testStruct.Purge)
+		assert.Equal(t, "This deletes all data, continue? [y/N]: ", output.String())
+		for _, answer := range []string{"\n", "no\n", ""} {
+			PromptInput = strings.NewReader(answer)
+			params, err = newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			_, err = params.ParseArguments(&foo{}, []string{"--purge"})
+			assert.True(t, errors.Is(err, ErrNotConfirmed))
+		}
+		output.Reset()
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--name", "me"})
+		assert.Nil(t, err)
+		assert.Empty(t, output.String())
+	})
+	t.Run("name=value", func(t *testing.T) {
+		type foo struct {
+			Output  string `yagclif:"shortname:o"`
+			Labels  map[string]string
+			Level   int `yagclif:"implicit:1"`
+			Verbose bool
+			Color   bool `yagclif:"negatable"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"--output=/tmp/x=y", "--labels=a=b", "--level=2", "a=b", "--unknown=c"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a=b", "--unknown=c"}, remaining)
+		assert.Equal(t, &foo{Output: "/tmp/x=y", Labels: map[string]string{"a": "b"}, Level: 2}, testStruct)
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct = &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"-o=", "--level="})
+		assert.NotNil(t, err)
+		for _, args := range [][]string{{"--verbose=yes"}, {"--no-color=true"}, {"--output"}} {
+			params, err = newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			_, err = params.ParseArguments(&foo{}, args)
+			assert.NotNil(t, err)
+		}
+	})
+	t.Run("bool values", func(t *testing.T) {
+		type foo struct {
+			Enable  bool `yagclif:"default:true"`
+			Verbose bool
+			Color   *bool
+			Src     string `yagclif:"positional"`
+		}
+		cases := []struct {
+			args     []string
+			expected foo
+		}{
+			{[]string{}, foo{Enable: true}},
+			{[]string{"--enable=false", "--verbose", "True"}, foo{Verbose: true}},
+			{[]string{"--enable", "false", "--color=0", "--verbose", "a.txt"}, foo{Verbose: true, Color: new(bool), Src: "a.txt"}},
+			{[]string{"--enable", "--verbose=1", "--color", "true"}, foo{Enable: true, Verbose: true, Color: &[]bool{true}[0]}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Empty(t, remaining)
+			assert.Equal(t, c.expected, *testStruct)
+		}
+	})
+	t.Run("rest positionals", func(t *testing.T) {
+		type foo struct {
+			Dst     string   `yagclif:"positional;mandatory"`
+			Files   []string `yagclif:"positional:rest;default:a.txt"`
+			Sizes   []int    `yagclif:"positional:rest"`
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+		type bar struct {
+			Dst     string   `yagclif:"positional;mandatory"`
+			Files   []string `yagclif:"positional:rest;default:a.txt"`
+			Verbose bool
+		}
+		params, err = newParameters(reflect.TypeOf(bar{}))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"FILES..."}, params[1].CliNames())
+		testStruct := &bar{}
+		remaining, err := params.ParseArguments(testStruct, []string{"out", "b;c.txt", "--verbose", "--", "--d.txt"})
+		assert.Nil(t, err)
+		assert.Empty(t, remaining)
+		assert.Equal(t, &bar{Dst: "out", Files: []string{"b;c.txt", "--d.txt"}, Verbose: true}, testStruct)
+		params, err = newParameters(reflect.TypeOf(bar{}))
+		assert.Nil(t, err)
+		testStruct = &bar{}
+		_, err = params.ParseArguments(testStruct, []string{"out"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a.txt"}, testStruct.Files)
+		type baz struct {
+			Files []string `yagclif:"positional:rest;minlen:3;maxlen:4"`
+		}
+		params, err = newParameters(reflect.TypeOf(baz{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&baz{}, []string{"a", "bb"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "minlen 3")
+		_, err = params.ParseArguments(&baz{}, []string{"a", "b", "c", "d", "e"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "maxlen 4")
+		_, err = params.ParseArguments(&baz{}, []string{"a", "b", "c"})
+		assert.Nil(t, err)
+	})
+	t.Run("positional orders", func(t *testing.T) {
+		type foo struct {
+			Mode    string   `yagclif:"positional"`
+			Dst     string   `yagclif:"positional:2;mandatory"`
+			Src     string   `yagclif:"positional:1;mandatory"`
+			Files   []string `yagclif:"positional:rest;arity:1+"`
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		positionals := params.positionals()
+		assert.Equal(t, []string{"SRC", "DST", "MODE", "FILES..."}, positionals.cliNames())
+		assert.Equal(t, "mytool SRC DST [MODE] FILES... [flags]", params.usageLine("mytool"))
+		testStruct := &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"a", "b", "c", "d"})
+		assert.Nil(t, err)
+		assert.Equal(t, &foo{Src: "a", Dst: "b", Mode: "c", Files: []string{"d"}}, testStruct)
+		type bar struct {
+			Src string `yagclif:"positional:1"`
+			Dst string `yagclif:"positional:1"`
+		}
+		params, err = newParameters(reflect.TypeOf(bar{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+		type baz struct {
+			Src string `yagclif:"positional:-1"`
+		}
+		params, err = newParameters(reflect.TypeOf(baz{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+	})
+	t.Run("positional arities", func(t *testing.T) {
+		type exactly struct {
+			Files []string `yagclif:"positional:rest;arity:2"`
+		}
+		type atLeast struct {
+			Files []string `yagclif:"positional:rest;arity:1+"`
+		}
+		type between struct {
+			Files []string `yagclif:"positional:rest;arity:1-2"`
+		}
+		cases := []struct {
+			tipe  reflect.Type
+			args  []string
+			error string
+		}{
+			{reflect.TypeOf(exactly{}), []string{"a", "b"}, ""},
+			{reflect.TypeOf(exactly{}), []string{"a"}, "FILES... : expected 2 arguments, got 1"},
+			{reflect.TypeOf(atLeast{}), []string{"a", "b", "c"}, ""},
+			{reflect.TypeOf(atLeast{}), []string{}, "FILES... : expected at least 1 arguments, got 0"},
+			{reflect.TypeOf(between{}), []string{"a"}, ""},
+			{reflect.TypeOf(between{}), []string{"a", "b", "c"}, "FILES... : expected between 1 and 2 arguments, got 3"},
+		}
+		for _, c := range cases {
+			params, err := newParameters(c.tipe)
+			assert.Nil(t, err)
+			_, err = params.ParseArguments(reflect.New(c.tipe).Interface(), c.args)
+			if c.error == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, c.error)
+			}
+		}
+		type invalid struct {
+			Src   []string `yagclif:"positional;arity:2"`
+			Files []string `yagclif:"positional:rest;arity:3-2"`
+			Dirs  []string `yagclif:"positional:rest;arity:x"`
+		}
+		for i := 0; i < 3; i++ {
+			param, err := newParameter(reflect.TypeOf(invalid{}).Field(i))
+			assert.NotNil(t, err)
+			assert.Nil(t, param)
+		}
+	})
+	t.Run("kebab case naming", func(t *testing.T) {
+		names := map[string]string{
+			"MaxRetryCount": "max-retry-count",
+			"HTTPServer":    "http-server",
+			"UserID":        "user-id",
+			"Level2Cache":   "level2-cache",
+			"url":           "url",
+		}
+		for name, expected := range names {
+			assert.Equal(t, expected, KebabCaseNaming(name))
+		}
+		Naming = KebabCaseNaming
+		defer func() { Naming = FieldNaming }()
+		type options struct {
+			MaxRetryCount int
+		}
+		type foo struct {
+			DBOptions options
+			DryRun    bool   `yagclif:"requires:OutputDir"`
+			LogFile   string `yagclif:"name:logfile"`
+			OutputDir string
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--db-options-max-retry-count", "3", "--dry-run", "--logfile", "out.log", "--output-dir", "out"})
+		assert.Nil(t, err)
+		assert.Equal(t, &foo{DBOptions: options{MaxRetryCount: 3}, DryRun: true, LogFile: "out.log", OutputDir: "out"}, testStruct)
+	})
+	t.Run("aggregated errors", func(t *testing.T) {
+		type foo struct {
+			Level   int    `yagclif:"mandatory"`
+			Name    string `yagclif:"mandatory"`
+			Output  string `yagclif:"mandatory"`
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--level", "x", "--verbose=maybe", "a.txt", "--output"})
+		assert.NotNil(t, err)
+		errs, ok := err.(Errors)
+		assert.True(t, ok)
+		assert.Len(t, errs, 4)
+		assert.Contains(t, errs[0].Error(), "Level")
+		assert.Contains(t, errs[1].Error(), "maybe")
+		assert.EqualError(t, errs[2], "missing value for --output")
+		assert.EqualError(t, errs[3], "missing argument [--name] for Name")
+	})
+	t.Run("parsed again", func(t *testing.T) {
+		type get struct {
+			Key string `yagclif:"positional;mandatory"`
+		}
+		type foo struct {
+			Level int `yagclif:"mandatory"`
+			Get   get `yagclif:"command"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--level", "1", "get", "a"})
+		assert.Nil(t, err)
+		testStruct = &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--level", "2", "get", "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, &foo{Level: 2, Get: get{Key: "b"}}, testStruct)
+		_, err = params.ParseArguments(&foo{}, []string{})
+		assert.EqualError(t, err, "missing argument [--level] for Level")
+	})
+	t.Run("parsed concurrently", func(t *testing.T) {
+		type foo struct {
+			Level int      `yagclif:"mandatory"`
+			Names []string `yagclif:"delimiter:,"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		var group sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			group.Add(1)
+			go func(level int) {
+				defer group.Done()
+				testStruct := &foo{}
+				_, err := params.ParseArguments(testStruct, []string{"--level", fmt.Sprint(level), "--names", "a", "--names", "b"})
+				assert.Nil(t, err)
+				assert.Equal(t, &foo{Level: level, Names: []string{"a", "b"}}, testStruct)
+			}(i)
+		}
+		group.Wait()
+		for _, param := range params {
+			assert.False(t, param.used)
+			assert.Nil(t, param.settings)
+		}
+	})
+	t.Run("terminator", func(t *testing.T) {
+		type foo struct {
+			Src     string `yagclif:"positional"`
+			Output  string
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"--output", "--", "--verbose", "--", "-a.txt", "--verbose", "--"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--verbose", "--"}, remaining)
+		assert.Equal(t, &foo{Src: "-a.txt", Output: "--", Verbose: true}, testStruct)
+	})
+	t.Run("not interspersed", func(t *testing.T) {
+		Interspersed = false
+		defer func() { Interspersed = true }()
+		type foo struct {
+			Src     string `yagclif:"positional"`
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"--verbose", "a.txt", "--verbose", "b.txt"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--verbose", "b.txt"}, remaining)
+		assert.Equal(t, &foo{Src: "a.txt", Verbose: true}, testStruct)
+	})
+	t.Run("stop at unknown", func(t *testing.T) {
+		StopAtUnknown = true
+		defer func() { StopAtUnknown = false }()
+		type foo struct {
+			Host    string `yagclif:"positional"`
+			Verbose bool
+		}
+		cases := []struct {
+			args      []string
+			expected  foo
+			remaining []string
+		}{
+			{[]string{"--verbose", "host", "ls", "--verbose", "-l"}, foo{Host: "host", Verbose: true}, []string{"ls", "--verbose", "-l"}},
+			{[]string{"-x", "host", "--verbose"}, foo{}, []string{"-x", "host", "--verbose"}},
+			{[]string{"host", "--", "--verbose"}, foo{Host: "host"}, []string{"--verbose"}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Equal(t, c.remaining, remaining)
+			assert.Equal(t, &c.expected, testStruct)
+		}
+	})
+	t.Run("windows flags", func(t *testing.T) {
+		type foo struct {
+			Out     string `yagclif:"shortname:o"`
+			Level   int    `yagclif:"implicit:1"`
+			Verbose bool   `yagclif:"negatable"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"/out:/tmp/x", "/verbose", "/tmp/y"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"/out:/tmp/x", "/verbose", "/tmp/y"}, remaining)
+		assert.Equal(t, &foo{}, testStruct)
+		WindowsFlags = true
+		defer func() { WindowsFlags = false }()
+		cases := []struct {
+			args      []string
+			expected  foo
+			remaining []string
+		}{
+			{[]string{"/out:/tmp/x", "/verbose", "/tmp/y"}, foo{Out: "/tmp/x", Verbose: true}, []string{"/tmp/y"}},
+			{[]string{"/o", "c:\\x", "/no-verbose"}, foo{Out: "c:\\x"}, []string{}},
+			{[]string{"/level", "/verbose"}, foo{Level: 1, Verbose: true}, []string{}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Equal(t, c.remaining, remaining)
+			assert.Equal(t, &c.expected, testStruct)
+		}
+		for _, args := range [][]string{{"/verbose:x"}, {"/no-verbose:true"}} {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			_, err = params.ParseArguments(&foo{}, args)
+			assert.NotNil(t, err)
+		}
+	})
+	t.Run("unknown flags", func(t *testing.T) {
+		type foo struct {
+			Src     string   `yagclif:"positional"`
+			Extra   []string `yagclif:"unknown"`
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"-x", "a.txt", "--level=2", "--jobs", "4", "--verbose", "b", "--", "--y"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--y"}, remaining)
+		assert.Equal(t, &foo{Src: "b", Extra: []string{"-x", "a.txt", "--level=2", "--jobs", "4"}, Verbose: true}, testStruct)
+		// the value is an operand when the positional needs it.
+		testStruct = &foo{}
+		remaining, err = params.ParseArguments(testStruct, []string{"-x", "a.txt", "--verbose"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{}, remaining)
+		assert.Equal(t, &foo{Src: "a.txt", Extra: []string{"-x"}, Verbose: true}, testStruct)
+		type bar struct {
+			Extra  []string `yagclif:"unknown"`
+			Others []string `yagclif:"unknown"`
+		}
+		params, err = newParameters(reflect.TypeOf(bar{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+		type baz struct {
+			Extra string `yagclif:"unknown"`
+		}
+		params, err = newParameters(reflect.TypeOf(baz{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, params)
+	})
+	t.Run("single dash long names", func(t *testing.T) {
+		type foo struct {
+			Verbose bool   `yagclif:"negatable"`
+			Output  string `yagclif:"shortname:out"`
+			Level   int
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		remaining, err := params.ParseArguments(&foo{}, []string{"-verbose", "-level=2"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"-verbose", "-level=2"}, remaining)
+		SingleDashLongNames = true
+		defer func() { SingleDashLongNames = false }()
+		cases := []struct {
+			args     []string
+			expected foo
+		}{
+			{[]string{"-verbose", "-level=2", "-out", "x"}, foo{Verbose: true, Output: "x", Level: 2}},
+			{[]string{"-no-verbose", "-output=y", "-level", "-3"}, foo{Output: "y", Level: -3}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Empty(t, remaining)
+			assert.Equal(t, c.expected, *testStruct)
+		}
+	})
+	t.Run("glued short values", func(t *testing.T) {
+		type foo struct {
+			Number  int    `yagclif:"shortname:n"`
+			Output  string `yagclif:"shortname:o"`
+			Offset  int    `yagclif:"shortname:of"`
+			Verbose bool   `yagclif:"shortname:v"`
+		}
+		cases := []struct {
+			args      []string
+			expected  foo
+			remaining []string
+		}{
+			{[]string{"-n5", "-ooutput.txt"}, foo{Number: 5, Output: "output.txt"}, []string{}},
+			{[]string{"-n-5", "-of3", "-o=x"}, foo{Number: -5, Offset: 3, Output: "x"}, []string{}},
+			{[]string{"-vx", "-x5"}, foo{}, []string{"-vx", "-x5"}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Equal(t, c.remaining, remaining)
+			assert.Equal(t, c.expected, *testStruct)
+		}
+	})
+	t.Run("POSIX", func(t *testing.T) {
+		POSIX = true
+		defer func() { POSIX = false }()
+		type foo struct {
+			Number  int    `yagclif:"shortname:n"`
+			Verbose bool   `yagclif:"shortname:v"`
+			Src     string `yagclif:"positional"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"-v", "-n5", "a", "-n", "6"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"-n", "6"}, remaining)
+		assert.Equal(t, &foo{Number: 5, Verbose: true, Src: "a"}, testStruct)
+		testStruct = &foo{}
+		remaining, err = params.ParseArguments(testStruct, []string{"-vn5", "a", "-v"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"-v"}, remaining)
+		assert.Equal(t, &foo{Number: 5, Verbose: true, Src: "a"}, testStruct)
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--verbose"})
+		assert.NotNil(t, err)
+		type long struct {
+			Number int `yagclif:"shortname:nb"`
+		}
+		type negatable struct {
+			Color bool `yagclif:"shortname:c;negatable"`
+		}
+		for _, tipe := range []reflect.Type{reflect.TypeOf(long{}), reflect.TypeOf(negatable{})} {
+			params, err := newParameters(tipe)
+			assert.NotNil(t, err)
+			assert.Nil(t, params)
+		}
+	})
+	t.Run("GNU", func(t *testing.T) {
+		Interspersed = false
+		GNU = true
+		defer func() { GNU, Interspersed = false, true }()
+		type foo struct {
+			Number  int    `yagclif:"shortname:n"`
+			Level   int    `yagclif:"shortname:l;implicit:1"`
+			Debug   int    `yagclif:"shortname:d;count"`
+			Verbose bool   `yagclif:"shortname:v"`
+			Src     string `yagclif:"positional"`
+		}
+		cases := []struct {
+			args      []string
+			expected  foo
+			remaining []string
+		}{
+			{[]string{"a", "--verb", "--lev", "2"}, foo{Verbose: true, Level: 1, Src: "a"}, []string{"2"}},
+			{[]string{"-vddn5", "-l4"}, foo{Verbose: true, Debug: 2, Number: 5, Level: 4}, []string{}},
+			{[]string{"-vx", "-dn", "6", "--level=3"}, foo{Verbose: true, Debug: 1, Number: 6, Level: 3, Src: "-x"}, []string{}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			args := append([]string{}, c.args...)
+			remaining, err := params.ParseArguments(testStruct, args)
+			assert.Nil(t, err)
+			assert.Equal(t, c.remaining, remaining)
+			assert.Equal(t, c.expected, *testStruct)
+			assert.Equal(t, c.args, args)
+		}
+	})
+	t.Run("abbreviations", func(t *testing.T) {
+		type foo struct {
+			Verbose  bool `yagclif:"negatable"`
+			Verbatim bool
+			Output   string
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		remaining, err := params.ParseArguments(&foo{}, []string{"--verbo", "--out", "x"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--verbo", "--out", "x"}, remaining)
+		Abbreviations = true
+		defer func() { Abbreviations = false }()
+		cases := []struct {
+			args     []string
+			expected foo
+		}{
+			{[]string{"--verbo", "--out", "x"}, foo{Verbose: true, Output: "x"}},
+			{[]string{"--no", "--verbatim", "--o=y"}, foo{Verbatim: true, Output: "y"}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Empty(t, remaining)
+			assert.Equal(t, c.expected, *testStruct)
+		}
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--verb"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "--verbose --verbatim")
+	})
+	t.Run("implicit values", func(t *testing.T) {
+		type foo struct {
+			Profile string `yagclif:"implicit:default"`
+			Level   int    `yagclif:"implicit:1;default:0"`
+			Verbose bool
+		}
+		cases := []struct {
+			args      []string
+			expected  foo
+			remaining []string
+		}{
+			{[]string{"--profile", "--verbose"}, foo{Profile: "default", Verbose: true}, []string{}},
+			{[]string{"--profile", "staging", "--level"}, foo{Profile: "staging", Level: 1}, []string{}},
+			{[]string{"--level", "-x"}, foo{Level: 1}, []string{"-x"}},
+			{[]string{"--level", "3", "foo"}, foo{Level: 3}, []string{"foo"}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Equal(t, c.remaining, remaining)
+			assert.Equal(t, c.expected, *testStruct)
+		}
+	})
+	t.Run("negative numbers", func(t *testing.T) {
+		type foo struct {
+			Level   int      `yagclif:"implicit:1;default:0"`
+			Ratio   float64  `yagclif:"shortname:r"`
+			Offset  int      `yagclif:"positional"`
+			Extra   []string `yagclif:"unknown"`
+			Verbose bool
+		}
+		cases := []struct {
+			args      []string
+			expected  foo
+			remaining []string
+		}{
+			{[]string{"--level", "-5", "-r", "-0.5"}, foo{Level: -5, Ratio: -0.5}, []string{}},
+			{[]string{"-3", "--level", "-x", "-1e3"}, foo{Level: 1, Offset: -3, Extra: []string{"-x", "-1e3"}}, []string{}},
+			{[]string{"-3", "--level", "-x=1", "-1e3"}, foo{Level: 1, Offset: -3, Extra: []string{"-x=1"}}, []string{"-1e3"}},
+		}
+		for _, c := range cases {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			remaining, err := params.ParseArguments(testStruct, c.args)
+			assert.Nil(t, err)
+			assert.Equal(t, c.remaining, remaining)
+			assert.Equal(t, c.expected, *testStruct)
+		}
+	})
+	t.Run("repeatable", func(t *testing.T) {
+		type foo struct {
+			Include []string `yagclif:"repeatable;delimiter:,;default:src"`
+			Exclude []string `yagclif:"delimiter:,"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--include", "a", "--include", "b,c"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, testStruct.Include)
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct = &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--exclude", "a", "--exclude", "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"src"}, testStruct.Include)
+		assert.Equal(t, []string{"a", "b"}, testStruct.Exclude)
+		type bar struct {
+			Tags []string `yagclif:"format:json"`
+		}
+		params, err = newParameters(reflect.TypeOf(bar{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&bar{}, []string{"--tags", `["a"]`, "--tags", `["b"]`})
+		assert.NotNil(t, err)
+	})
+	t.Run("positionals", func(t *testing.T) {
+		type foo struct {
+			Src     string `yagclif:"positional;mandatory"`
+			Verbose bool
+			Dst     string `yagclif:"positional"`
+			Mode    int    `yagclif:"positional;min:0"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"a.txt", "--verbose", "b.txt"})
+		assert.Nil(t, err)
+		assert.Empty(t, remaining)
+		assert.Equal(t, &foo{Src: "a.txt", Verbose: true, Dst: "b.txt"}, testStruct)
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct = &foo{}
+		remaining, err = params.ParseArguments(testStruct, []string{"a.txt", "b.txt", "7", "c.txt"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"c.txt"}, remaining)
+		assert.Equal(t, 7, testStruct.Mode)
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"a.txt", "b.txt", "-1"})
+		assert.NotNil(t, err)
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--verbose"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "SRC")
+	})
+	t.Run("oneof groups", func(t *testing.T) {
+		type foo struct {
+			File  string `yagclif:"oneof:input"`
+			URL   string `yagclif:"oneof:input;env:FOO_URL"`
+			Stdin bool   `yagclif:"oneof:input"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{})
+		assert.NotNil(t, err)
+		assert.Equal(t, "missing one of --file --url --stdin for input", err.Error())
+		for _, args := range [][]string{{"--stdin"}, {"--file", "a", "--url", "b"}} {
+			params, err = newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			_, err = params.ParseArguments(&foo{}, args)
+			assert.Nil(t, err)
+		}
+		os.Setenv("FOO_URL", "http://localhost")
+		defer os.Unsetenv("FOO_URL")
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{})
+		assert.Nil(t, err)
+	})
+	t.Run("xor groups", func(t *testing.T) {
+		type foo struct {
+			FromFile string `yagclif:"xor:source;env:FOO_FILE"`
+			FromURL  string `yagclif:"xor:source;env:FOO_URL"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{})
+		assert.NotNil(t, err)
+		assert.Equal(t, "missing one of --fromfile --fromurl for source", err.Error())
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--fromfile", "a", "--fromurl", "b"})
+		assert.NotNil(t, err)
+		assert.Equal(t, "only one of --fromfile --fromurl can be used for source", err.Error())
+		os.Setenv("FOO_FILE", "a")
+		defer os.Unsetenv("FOO_FILE")
+		for _, args := range [][]string{{}, {"--fromurl", "b"}} {
+			params, err = newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			_, err = params.ParseArguments(&foo{}, args)
+			assert.Nil(t, err)
+		}
+		os.Setenv("FOO_URL", "b")
+		defer os.Unsetenv("FOO_URL")
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{})
+		assert.NotNil(t, err)
+	})
+	t.Run("conflicts", func(t *testing.T) {
+		type foo struct {
+			Verbose bool `yagclif:"conflicts:quiet"`
+			Quiet   bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--quiet", "--verbose"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "--verbose can not be used with --quiet")
+		params, err = newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, err = params.ParseArguments(&foo{}, []string{"--quiet"})
+		assert.Nil(t, err)
+	})
+	t.Run("counts", func(t *testing.T) {
+		type foo struct {
+			Verbosity int `yagclif:"shortname:v;count"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"-v", "hello", "-v", "--verbosity"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"hello"}, remaining)
+		assert.Equal(t, 3, testStruct.Verbosity)
+	})
+	t.Run("defaults", func(t *testing.T) {
+		type foo struct {
+			Port    uint16            `yagclif:"default:8080"`
+			Ratio   float64           `yagclif:"default:0.5"`
+			Tags    []string          `yagclif:"delimiter:,;default:a,b"`
+			Labels  map[string]string `yagclif:"default:env=dev"`
+			Timeout time.Duration     `yagclif:"default:30s"`
+			Name    *string           `yagclif:"default:foo"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		_, err = params.ParseArguments(testStruct, []string{"--tags", "c"})
+		assert.Nil(t, err)
+		assert.Equal(t, uint16(8080), testStruct.Port)
+		assert.Equal(t, 0.5, testStruct.Ratio)
+		assert.Equal(t, []string{"c"}, testStruct.Tags)
+		assert.Equal(t, map[string]string{"env": "dev"}, testStruct.Labels)
+		assert.Equal(t, 30*time.Second, testStruct.Timeout)
+		assert.Equal(t, "foo", *testStruct.Name)
+	})
+	t.Run("environment variables", func(t *testing.T) {
+		type foo struct {
+			Port    int    `yagclif:"env:YAGCLIF_TEST_PORT;default:8080"`
+			Host    string `yagclif:"env:YAGCLIF_TEST_HOST;mandatory"`
+			Verbose bool   `yagclif:"env:YAGCLIF_TEST_VERBOSE"`
+		}
+		os.Setenv("YAGCLIF_TEST_PORT", "9090")
+		os.Setenv("YAGCLIF_TEST_HOST", "localhost")
+		os.Setenv("YAGCLIF_TEST_VERBOSE", "false")
+		defer os.Unsetenv("YAGCLIF_TEST_PORT")
+		defer os.Unsetenv("YAGCLIF_TEST_HOST")
+		defer os.Unsetenv("YAGCLIF_TEST_VERBOSE")
+		t.Run("env over default", func(t *testing.T) {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{Verbose: true}
+			_, err = params.ParseArguments(testStruct, []string{})
+			assert.Nil(t, err)
+			assert.Equal(t, &foo{Port: 9090, Host: "localhost"}, testStruct)
+		})
+		t.Run("flag over env", func(t *testing.T) {
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			testStruct := &foo{}
+			_, err = params.ParseArguments(testStruct, []string{"--port", "80"})
+			assert.Nil(t, err)
+			assert.Equal(t, 80, testStruct.Port)
+		})
+		t.Run("invalid env value", func(t *testing.T) {
+			os.Setenv("YAGCLIF_TEST_PORT", "http")
+			params, err := newParameters(reflect.TypeOf(foo{}))
+			assert.Nil(t, err)
+			_, err = params.ParseArguments(&foo{}, []string{})
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "YAGCLIF_TEST_PORT")
+		})
+	})
+	t.Run("error on default", func(t *testing.T) {
+		params, err := newParameters(reflect.TypeOf(validStruct{}))
+		assert.Nil(t, err)
+		// stub an unparselable value
+		params[0].defaultValue = "hello"
+		remaining, err := params.ParseArguments(&validStruct{}, []string{})
+		assert.Nil(t, remaining)
+		assert.NotNil(t, err)
+	})
 	t.Run("error at setter callback generating", func(t *testing.T) {
 		// type faultyStruct struct {
 		// 	a int
@@ -220,3 +1405,236 @@ func TestParse(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestArgsEnv(t *testing.T) {
+	type foo struct {
+		Level   int
+		Verbose bool
+	}
+	defer os.Unsetenv("YAGCLIF_TEST_ARGS")
+	os.Setenv("YAGCLIF_TEST_ARGS", `--level 2 "a b"`)
+	os.Args = []string{"main", "--verbose", "c"}
+	t.Run("disabled", func(t *testing.T) {
+		testStruct := &foo{}
+		remaining, err := Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"c"}, remaining)
+		assert.Equal(t, &foo{Verbose: true}, testStruct)
+	})
+	ArgsEnv = "YAGCLIF_TEST_ARGS"
+	defer func() { ArgsEnv = "" }()
+	t.Run("works", func(t *testing.T) {
+		testStruct := &foo{}
+		remaining, err := Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a b", "c"}, remaining)
+		assert.Equal(t, &foo{Level: 2, Verbose: true}, testStruct)
+	})
+	t.Run("command line replaces them", func(t *testing.T) {
+		os.Setenv("YAGCLIF_TEST_ARGS", `--level 1 --verbose`)
+		os.Args = []string{"main", "--level", "2", "c"}
+		testStruct := &foo{}
+		remaining, err := Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"c"}, remaining)
+		assert.Equal(t, &foo{Level: 2, Verbose: true}, testStruct)
+	})
+	t.Run("command line replaces the counts", func(t *testing.T) {
+		type bar struct {
+			Verbosity int      `yagclif:"count;shortname:v"`
+			Tags      []string `yagclif:"delimiter:,;default:x"`
+		}
+		os.Setenv("YAGCLIF_TEST_ARGS", `-v -v --tags a,b`)
+		os.Args = []string{"main", "-v", "--tags", "c"}
+		testStruct := &bar{}
+		_, err := Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, &bar{Verbosity: 1, Tags: []string{"c"}}, testStruct)
+	})
+	t.Run("groups checked on the final values", func(t *testing.T) {
+		type bar struct {
+			FromFile string `yagclif:"xor:source"`
+			FromURL  string `yagclif:"xor:source"`
+			Verbose  bool   `yagclif:"conflicts:quiet"`
+			Quiet    bool
+		}
+		os.Setenv("YAGCLIF_TEST_ARGS", `--fromfile a`)
+		os.Args = []string{"main", "--fromurl", "b"}
+		_, err := Parse(&bar{})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "only one of")
+		os.Setenv("YAGCLIF_TEST_ARGS", `--fromfile a --verbose`)
+		os.Args = []string{"main", "--quiet"}
+		_, err = Parse(&bar{})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "conflicts")
+		os.Args = []string{"main", "--fromfile", "b", "--verbose"}
+		testStruct := &bar{}
+		_, err = Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, &bar{FromFile: "b", Verbose: true}, testStruct)
+	})
+	t.Run("flags confirmed", func(t *testing.T) {
+		type bar struct {
+			Force bool `yagclif:"confirm:really?"`
+		}
+		defer func(input io.Reader, output io.Writer) {
+			PromptInput, PromptOutput = input, output
+		}(PromptInput, PromptOutput)
+		var output bytes.Buffer
+		PromptInput, PromptOutput = strings.NewReader("n\n"), &output
+		os.Setenv("YAGCLIF_TEST_ARGS", `--force`)
+		os.Args = []string{"main", "a.txt"}
+		_, err := Parse(&bar{})
+		assert.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrNotConfirmed))
+		assert.Equal(t, "really? [y/N]: ", output.String())
+	})
+	t.Run("return err", func(t *testing.T) {
+		os.Setenv("YAGCLIF_TEST_ARGS", `--level "2`)
+		remaining, err := Parse(&foo{})
+		assert.NotNil(t, err)
+		assert.Nil(t, remaining)
+	})
+}
+
+func TestParseKnown(t *testing.T) {
+	type plugin struct {
+		Level int
+	}
+	type app struct {
+		Src     string `yagclif:"positional"`
+		Verbose bool
+	}
+	t.Run("works", func(t *testing.T) {
+		appStruct := &app{}
+		remaining, err := ParseKnown(appStruct, []string{"--level=2", "--verbose", "a.txt", "-x", "b.txt"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--level=2", "-x", "b.txt"}, remaining)
+		assert.Equal(t, &app{Src: "a.txt", Verbose: true}, appStruct)
+		pluginStruct := &plugin{}
+		remaining, err = ParseKnown(pluginStruct, remaining)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"-x", "b.txt"}, remaining)
+		assert.Equal(t, &plugin{Level: 2}, pluginStruct)
+	})
+	t.Run("values of unknown flags", func(t *testing.T) {
+		appStruct := &app{}
+		remaining, err := ParseKnown(appStruct, []string{"--level", "2", "a.txt", "-x", "b.txt"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--level", "2", "-x", "b.txt"}, remaining)
+		assert.Equal(t, &app{Src: "a.txt"}, appStruct)
+		pluginStruct := &plugin{}
+		remaining, err = ParseKnown(pluginStruct, remaining)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"-x", "b.txt"}, remaining)
+		assert.Equal(t, &plugin{Level: 2}, pluginStruct)
+	})
+	t.Run("return err", func(t *testing.T) {
+		remaining, err := ParseKnown(&plugin{}, []string{"--level", "high"})
+		assert.Nil(t, remaining)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "usage")
+	})
+}
+
+type validatableRange struct {
+	Min int
+	Max int
+}
+
+func (r *validatableRange) Validate() error {
+	if r.Min > r.Max {
+		return fmt.Errorf("min %d is greater than max %d", r.Min, r.Max)
+	}
+	return nil
+}
+
+type validatableCommand struct {
+	Range *validatableRange `yagclif:"command"`
+	Level int               `yagclif:"mandatory"`
+}
+
+func TestValidatable(t *testing.T) {
+	t.Run("works", func(t *testing.T) {
+		testStruct := &validatableRange{}
+		_, err := ParseArgs(testStruct, []string{"--min", "1", "--max", "2"})
+		assert.Nil(t, err)
+		assert.Equal(t, &validatableRange{Min: 1, Max: 2}, testStruct)
+	})
+	t.Run("return err", func(t *testing.T) {
+		_, err := ParseArgs(&validatableRange{}, []string{"--min", "3", "--max", "2"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "min 3 is greater than max 2")
+		_, err = ParseArgs(&validatableRange{}, []string{"--min", "x"})
+		assert.NotNil(t, err)
+		assert.NotContains(t, err.Error(), "greater")
+	})
+	t.Run("commands", func(t *testing.T) {
+		_, err := ParseArgs(&validatableCommand{}, []string{"--level", "1", "range", "--min", "3", "--max", "2"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "min 3 is greater than max 2")
+	})
+	t.Run("nested structs", func(t *testing.T) {
+		type foo struct {
+			Range  validatableRange
+			Window *validatableRange
+		}
+		_, err := ParseArgs(&foo{}, []string{"--range-min", "3", "--range-max", "2"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "min 3 is greater than max 2")
+		_, err = ParseArgs(&foo{}, []string{"--window-min", "3", "--window-max", "2"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "min 3 is greater than max 2")
+		testStruct := &foo{}
+		_, err = ParseArgs(testStruct, []string{"--range-max", "2"})
+		assert.Nil(t, err)
+		assert.Nil(t, testStruct.Window)
+	})
+}
+
+type defaulterServer struct {
+	Host string `yagclif:"default:localhost"`
+	Port int    `yagclif:"default:80;env:YAGCLIF_TEST_PORT"`
+	Name string
+}
+
+func (server *defaulterServer) SetDefaults() {
+	server.Host = "example.com"
+	server.Port = 8080
+	server.Name = "server"
+}
+
+func TestDefaulter(t *testing.T) {
+	t.Run("overrides the default constraints", func(t *testing.T) {
+		testStruct := &defaulterServer{}
+		_, err := ParseArgs(testStruct, []string{})
+		assert.Nil(t, err)
+		assert.Equal(t, &defaulterServer{Host: "example.com", Port: 8080, Name: "server"}, testStruct)
+	})
+	t.Run("overridden by the environment and the arguments", func(t *testing.T) {
+		defer os.Unsetenv("YAGCLIF_TEST_PORT")
+		os.Setenv("YAGCLIF_TEST_PORT", "9090")
+		testStruct := &defaulterServer{}
+		_, err := ParseArgs(testStruct, []string{"--name", "api"})
+		assert.Nil(t, err)
+		assert.Equal(t, &defaulterServer{Host: "example.com", Port: 9090, Name: "api"}, testStruct)
+	})
+	t.Run("nested structs", func(t *testing.T) {
+		type foo struct {
+			Server defaulterServer
+			Backup *defaulterServer
+		}
+		testStruct := &foo{}
+		_, err := ParseArgs(testStruct, []string{"--server-name", "api"})
+		assert.Nil(t, err)
+		// the default constraints allocate the backup.
+		assert.Equal(t, &foo{
+			Server: defaulterServer{Host: "example.com", Port: 8080, Name: "api"},
+			Backup: &defaulterServer{Host: "example.com", Port: 8080, Name: "server"},
+		}, testStruct)
+		args, err := Marshal(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--server-name=api"}, args)
+	})
+}
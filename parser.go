@@ -0,0 +1,366 @@
+package yagclif
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Exit code of MustParse and of the
+// parsers exiting on errors.
+var ExitCode = 2
+
+// Parser parses the arguments with a behavior
+// configured once by its options.
+type Parser struct {
+	programName    string
+	output         io.Writer
+	helpOutput     io.Writer
+	warningOutput  io.Writer
+	exit           func(code int)
+	exitCode       int
+	beforeParse    []BeforeParseHook
+	afterParse     []AfterParseHook
+	fieldCallbacks []fieldCallback
+	options        *parseOptions
+}
+
+// Callback registered for the field of the name.
+type fieldCallback struct {
+	name     string
+	callback FieldCallback
+}
+
+// BeforeParseHook gets the arguments before they are
+// parsed into the object and returns the ones to parse.
+type BeforeParseHook func(ctx context.Context, obj interface{}, args []string) ([]string, error)
+
+// AfterParseHook gets the object once it is filled.
+type AfterParseHook func(ctx context.Context, obj interface{}) error
+
+// FieldCallback gets the raw argument and the value of a field
+// each time it is set from the arguments, the raw argument
+// is empty for flags without value.
+type FieldCallback func(ctx context.Context, raw string, value interface{}) error
+
+// Option configures a Parser, the package
+// variables are the defaults read by NewParser.
+type Option func(parser *Parser)
+
+// WithProgramName sets the name of the program
+// in the usage line, defaults to the base name of os.Args[0].
+func WithProgramName(name string) Option {
+	return func(parser *Parser) {
+		parser.programName = name
+	}
+}
+
+// WithOutput sets the writer of the errors
+// before exiting, defaults to os.Stderr.
+func WithOutput(output io.Writer) Option {
+	return func(parser *Parser) {
+		parser.output = output
+	}
+}
+
+// WithHelpOutput sets the writer of
+// PrintHelp, defaults to os.Stdout.
+func WithHelpOutput(output io.Writer) Option {
+	return func(parser *Parser) {
+		parser.helpOutput = output
+	}
+}
+
+// WithWarningOutput sets the writer of the warnings
+// such as deprecations, defaults to WarningOutput.
+func WithWarningOutput(output io.Writer) Option {
+	return func(parser *Parser) {
+		parser.warningOutput = output
+	}
+}
+
+// WithBeforeParse adds a hook run before parsing,
+// to normalize the arguments for instance.
+func WithBeforeParse(hook BeforeParseHook) Option {
+	return func(parser *Parser) {
+		parser.beforeParse = append(parser.beforeParse, hook)
+	}
+}
+
+// WithAfterParse adds a hook run after successful
+// parsings, to log the configuration for instance.
+func WithAfterParse(hook AfterParseHook) Option {
+	return func(parser *Parser) {
+		parser.afterParse = append(parser.afterParse, hook)
+	}
+}
+
+// WithFieldCallback adds a callback called each time the field
+// is set from the arguments, it is named by its struct field
+// name or like in the constraints.
+func WithFieldCallback(name string, callback FieldCallback) Option {
+	return func(parser *Parser) {
+		parser.fieldCallbacks = append(parser.fieldCallbacks, fieldCallback{name, callback})
+	}
+}
+
+// WithExitBehavior sets the function called with the exit
+// code after writing an error, os.Exit for instance.
+// Without it the errors are returned.
+func WithExitBehavior(exit func(code int)) Option {
+	return func(parser *Parser) {
+		parser.exit = exit
+	}
+}
+
+// WithExitCode sets the code the parser exits
+// with on errors, defaults to ExitCode.
+func WithExitCode(code int) Option {
+	return func(parser *Parser) {
+		parser.exitCode = code
+	}
+}
+
+// WithPOSIX enforces the POSIX utility syntax
+// guidelines, defaults to POSIX.
+func WithPOSIX(posix bool) Option {
+	return func(parser *Parser) {
+		parser.options.posix = posix
+	}
+}
+
+// WithGNU reproduces the getopt_long behavior
+// of GNU tools, defaults to GNU.
+func WithGNU(gnu bool) Option {
+	return func(parser *Parser) {
+		parser.options.gnu = gnu
+	}
+}
+
+// WithInterspersed allows flags after the
+// operands, defaults to Interspersed.
+func WithInterspersed(interspersed bool) Option {
+	return func(parser *Parser) {
+		parser.options.interspersed = interspersed
+	}
+}
+
+// WithAbbreviations allows abbreviated long
+// names, defaults to Abbreviations.
+func WithAbbreviations(abbreviations bool) Option {
+	return func(parser *Parser) {
+		parser.options.abbreviations = abbreviations
+	}
+}
+
+// WithSingleDashLongNames allows long names written with
+// a single hyphen, defaults to SingleDashLongNames.
+func WithSingleDashLongNames(singleDash bool) Option {
+	return func(parser *Parser) {
+		parser.options.singleDashLongNames = singleDash
+	}
+}
+
+// WithWindowsFlags allows flags written /name and
+// /name:value, defaults to WindowsFlags.
+func WithWindowsFlags(windowsFlags bool) Option {
+	return func(parser *Parser) {
+		parser.options.windowsFlags = windowsFlags
+	}
+}
+
+// WithStopAtUnknown stops the parsing at the first
+// unknown flag, defaults to StopAtUnknown.
+func WithStopAtUnknown(stopAtUnknown bool) Option {
+	return func(parser *Parser) {
+		parser.options.stopAtUnknown = stopAtUnknown
+	}
+}
+
+// WithMatching sets the policy used to match the arguments
+// with the cli names, defaults to Matching.
+func WithMatching(matching MatchingPolicy) Option {
+	return func(parser *Parser) {
+		parser.options.matching = matching
+	}
+}
+
+// WithNaming sets the strategy used to name
+// the parameters, defaults to Naming.
+func WithNaming(naming NamingStrategy) Option {
+	return func(parser *Parser) {
+		parser.options.naming = naming
+	}
+}
+
+// WithArgsEnv sets the environment variable whose arguments
+// are prepended to the command line, defaults to ArgsEnv.
+func WithArgsEnv(name string) Option {
+	return func(parser *Parser) {
+		parser.options.argsEnv = name
+	}
+}
+
+// WithResponseFiles replaces the @file arguments by the
+// lines of the file, defaults to ResponseFiles.
+func WithResponseFiles(responseFiles bool) Option {
+	return func(parser *Parser) {
+		parser.options.responseFiles = responseFiles
+	}
+}
+
+// NewParser creates a parser configured by the options.
+func NewParser(options ...Option) *Parser {
+	parser := &Parser{
+		programName:   programName(),
+		output:        os.Stderr,
+		helpOutput:    os.Stdout,
+		warningOutput: WarningOutput,
+		exitCode:      ExitCode,
+		options:       defaultOptions(),
+	}
+	for _, option := range options {
+		option(parser)
+	}
+	return parser
+}
+
+// Returns the base name of the running program.
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+// Parse fills the object with the arguments of the program.
+func (parser *Parser) Parse(obj interface{}) (remainingArgs []string, err error) {
+	_, remainingArgs, err = parser.ParseCommand(obj)
+	return remainingArgs, err
+}
+
+// ParseCommand fills the object like Parse and returns the names
+// of the selected commands from the top level one to the leaf one.
+func (parser *Parser) ParseCommand(obj interface{}) (commands []string, remainingArgs []string, err error) {
+	args, envArgs, err := withEnvArgs(os.Args[1:], parser.options.argsEnv)
+	if err != nil {
+		return nil, nil, parser.fail(err)
+	}
+	params, remainingArgs, err := parser.parse(context.Background(), obj, args, envArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return params.selectedCommands(), remainingArgs, nil
+}
+
+// ParseContext fills the object like Parse, the validators,
+// default functions and prompts get the context.
+func (parser *Parser) ParseContext(ctx context.Context, obj interface{}) (remainingArgs []string, err error) {
+	args, envArgs, err := withEnvArgs(os.Args[1:], parser.options.argsEnv)
+	if err != nil {
+		return nil, parser.fail(err)
+	}
+	_, remainingArgs, err = parser.parse(ctx, obj, args, envArgs)
+	return remainingArgs, err
+}
+
+// ParseArgs fills the object with the arguments instead of
+// the ones of the program, they do not start with its name.
+func (parser *Parser) ParseArgs(obj interface{}, args []string) (remainingArgs []string, err error) {
+	_, remainingArgs, err = parser.ParseCommandArgs(obj, args)
+	return remainingArgs, err
+}
+
+// ParseCommandArgs fills the object like ParseArgs and returns
+// the names of the selected commands like ParseCommand.
+func (parser *Parser) ParseCommandArgs(obj interface{}, args []string) (commands []string, remainingArgs []string, err error) {
+	params, remainingArgs, err := parser.parse(context.Background(), obj, args, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return params.selectedCommands(), remainingArgs, nil
+}
+
+// ParseArgsContext fills the object like ParseArgs,
+// the hooks get the context like with ParseContext.
+func (parser *Parser) ParseArgsContext(ctx context.Context, obj interface{}, args []string) (remainingArgs []string, err error) {
+	_, remainingArgs, err = parser.parse(ctx, obj, args, 0)
+	return remainingArgs, err
+}
+
+// Fills the object with the arguments, the first envArgs ones
+// coming from ArgsEnv, and returns the session of the parsing.
+func (parser *Parser) parse(ctx context.Context, obj interface{}, args []string, envArgs int) (parameters, []string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, parser.fail(err)
+	}
+	tipe := reflect.TypeOf(obj).Elem()
+	params, err := newParametersWithOptions(tipe, parser.options)
+	if err != nil {
+		return nil, nil, parser.fail(err)
+	}
+	// the callbacks are resolved to their parameters once.
+	for _, fieldCallback := range parser.fieldCallbacks {
+		if !params.addCallback(fieldCallback.name, fieldCallback.callback) {
+			return nil, nil, parser.fail(fmt.Errorf("callback of unknown parameter %s", fieldCallback.name))
+		}
+	}
+	for _, hook := range parser.beforeParse {
+		if args, err = hook(ctx, obj, args); err != nil {
+			return nil, nil, parser.fail(err)
+		}
+	}
+	settings := newParseSettings(ctx, false)
+	settings.warnings = parser.warningOutput
+	settings.interspersed = parser.options.interspersedFlags()
+	// the hooks may have removed arguments coming from ArgsEnv.
+	if envArgs > len(args) {
+		envArgs = len(args)
+	}
+	settings.envArgs = envArgs
+	session, remainingArgs, err := params.parseSession(obj, args, settings)
+	if err != nil {
+		return nil, nil, parser.fail(params.usageError(err, parser.programName))
+	}
+	for _, hook := range parser.afterParse {
+		if err := hook(ctx, obj); err != nil {
+			return nil, nil, parser.fail(err)
+		}
+	}
+	return session, remainingArgs, nil
+}
+
+// PrintHelp writes the usage line and the
+// help of the object to the help output.
+func (parser *Parser) PrintHelp(obj interface{}) error {
+	tipe := reflect.TypeOf(obj).Elem()
+	params, err := newParametersWithOptions(tipe, parser.options)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(parser.helpOutput, strings.Join(params.getUsage(parser.programName), "\r\n"), "\r\n")
+	return err
+}
+
+// Writes the error and exits if the parser has an
+// exit behavior, the error is returned otherwise.
+func (parser *Parser) fail(err error) error {
+	if parser.exit == nil {
+		return err
+	}
+	fmt.Fprint(parser.output, err)
+	parser.exit(parser.exitCode)
+	return err
+}
+
+// MustParse fills the object like Parse, on errors they are
+// written with the help before exiting even without exit behavior.
+func (parser *Parser) MustParse(obj interface{}) (remainingArgs []string) {
+	exiting := *parser
+	if exiting.exit == nil {
+		exiting.exit = os.Exit
+	}
+	remainingArgs, _ = exiting.Parse(obj)
+	return remainingArgs
+}
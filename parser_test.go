@@ -0,0 +1,268 @@
+package yagclif
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewParser(t *testing.T) {
+	type foo struct {
+		Level int `yagclif:"mandatory"`
+	}
+	t.Run("defaults", func(t *testing.T) {
+		parser := NewParser()
+		assert.Equal(t, programName(), parser.programName)
+		assert.Equal(t, os.Stderr, parser.output)
+		assert.Equal(t, os.Stdout, parser.helpOutput)
+		assert.Equal(t, WarningOutput, parser.warningOutput)
+		assert.Nil(t, parser.exit)
+		assert.Equal(t, ExitCode, parser.exitCode)
+	})
+	t.Run("works", func(t *testing.T) {
+		os.Args = []string{"./main", "--level", "2", "a"}
+		testStruct := &foo{}
+		remaining, err := NewParser().Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, remaining)
+		assert.Equal(t, &foo{Level: 2}, testStruct)
+	})
+	t.Run("return err", func(t *testing.T) {
+		os.Args = []string{"./main"}
+		remaining, err := NewParser(WithProgramName("mytool")).Parse(&foo{})
+		assert.NotNil(t, err)
+		assert.Nil(t, remaining)
+		assert.Contains(t, err.Error(), "mytool [flags]")
+		var errs Errors
+		assert.False(t, errors.As(err, &errs))
+		_, err = NewParser().ParseArgs(&foo{}, []string{"--level", "x", "--level", "y"})
+		assert.True(t, errors.As(err, &errs))
+		assert.Len(t, errs, 2)
+	})
+	t.Run("explicit args", func(t *testing.T) {
+		os.Args = []string{"./main", "--level", "1"}
+		testStruct := &foo{}
+		remaining, err := ParseArgs(testStruct, []string{"--level", "2", "a"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, remaining)
+		assert.Equal(t, &foo{Level: 2}, testStruct)
+		remaining, err = NewParser().ParseArgs(&foo{}, []string{})
+		assert.NotNil(t, err)
+		assert.Nil(t, remaining)
+	})
+	t.Run("context", func(t *testing.T) {
+		os.Args = []string{"./main", "--level", "2"}
+		testStruct := &foo{}
+		_, err := ParseContext(context.Background(), testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, &foo{Level: 2}, testStruct)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = NewParser().ParseArgsContext(ctx, &foo{}, []string{"--level", "2"})
+		assert.Equal(t, context.Canceled, err)
+	})
+	t.Run("deadline during a prompt", func(t *testing.T) {
+		type bar struct {
+			Name  string `yagclif:"prompt"`
+			Force bool   `yagclif:"confirm:really?"`
+		}
+		defer func(input io.Reader, output io.Writer) {
+			PromptInput, PromptOutput = input, output
+		}(PromptInput, PromptOutput)
+		reader, writer := io.Pipe()
+		defer writer.Close()
+		PromptInput, PromptOutput = reader, ioutil.Discard
+		for _, args := range [][]string{{}, {"--name", "me", "--force"}} {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			_, err := NewParser().ParseArgsContext(ctx, &bar{}, args)
+			cancel()
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		}
+	})
+	t.Run("parsing options", func(t *testing.T) {
+		type bar struct {
+			MaxLevel int
+			Verbose  bool
+		}
+		os.Setenv("YAGCLIF_TEST_PARSER_ARGS", "--verbose")
+		defer os.Unsetenv("YAGCLIF_TEST_PARSER_ARGS")
+		os.Args = []string{"./main", "--max-level", "2", "a", "--VERB"}
+		testStruct := &bar{}
+		remaining, err := NewParser(
+			WithNaming(KebabCaseNaming),
+			WithMatching(CaseInsensitiveMatching),
+			WithAbbreviations(true),
+			WithInterspersed(false),
+			WithArgsEnv("YAGCLIF_TEST_PARSER_ARGS"),
+		).Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "--VERB"}, remaining)
+		assert.Equal(t, &bar{MaxLevel: 2, Verbose: true}, testStruct)
+		testStruct = &bar{}
+		remaining, err = NewParser().Parse(testStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"--max-level", "2", "a", "--VERB"}, remaining)
+		assert.Equal(t, &bar{}, testStruct)
+		_, err = NewParser(WithPOSIX(true)).ParseArgs(&foo{}, []string{})
+		assert.NotNil(t, err)
+		_, err = NewParser().ParseArgs(&foo{}, []string{"--level", "1"})
+		assert.Nil(t, err)
+	})
+	t.Run("exits", func(t *testing.T) {
+		os.Args = []string{"./main"}
+		var output bytes.Buffer
+		exitCode := 0
+		parser := NewParser(
+			WithProgramName("mytool"),
+			WithOutput(&output),
+			WithExitBehavior(func(code int) { exitCode = code }),
+		)
+		_, err := parser.Parse(&foo{})
+		assert.NotNil(t, err)
+		assert.Equal(t, ExitCode, exitCode)
+		assert.Equal(t, err.Error(), output.String())
+	})
+}
+
+func TestMustParse(t *testing.T) {
+	type foo struct {
+		Level int `yagclif:"mandatory"`
+	}
+	t.Run("works", func(t *testing.T) {
+		os.Args = []string{"./main", "--level", "2", "a"}
+		testStruct := &foo{}
+		assert.Equal(t, []string{"a"}, MustParse(testStruct))
+		assert.Equal(t, &foo{Level: 2}, testStruct)
+	})
+	t.Run("exits", func(t *testing.T) {
+		os.Args = []string{"./main"}
+		var output bytes.Buffer
+		exitCode := 0
+		parser := NewParser(
+			WithOutput(&output),
+			WithExitBehavior(func(code int) { exitCode = code }),
+			WithExitCode(64),
+		)
+		assert.Nil(t, parser.MustParse(&foo{}))
+		assert.Equal(t, 64, exitCode)
+		assert.Contains(t, output.String(), "--level int (mandatory)")
+	})
+}
+
+func TestPrintHelp(t *testing.T) {
+	type foo struct {
+		Level int    `yagclif:"mandatory"`
+		Name  string `yagclif:"deprecated"`
+	}
+	var help, warnings bytes.Buffer
+	parser := NewParser(
+		WithProgramName("mytool"),
+		WithHelpOutput(&help),
+		WithWarningOutput(&warnings),
+	)
+	assert.Nil(t, parser.PrintHelp(&foo{}))
+	assert.Equal(t, "mytool [flags]\r\n--level int (mandatory) \r\n--name string (DEPRECATED) \r\n", help.String())
+	_, err := parser.ParseArgs(&foo{}, []string{"--level", "1", "--name", "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, "warning: --name is deprecated\n", warnings.String())
+}
+
+func TestParseHooks(t *testing.T) {
+	type foo struct {
+		Level int
+	}
+	var logged []string
+	parser := NewParser(
+		WithBeforeParse(func(ctx context.Context, obj interface{}, args []string) ([]string, error) {
+			normalized := make([]string, len(args))
+			for i, arg := range args {
+				normalized[i] = strings.TrimPrefix(arg, "+")
+			}
+			return normalized, nil
+		}),
+		WithAfterParse(func(ctx context.Context, obj interface{}) error {
+			logged = append(logged, fmt.Sprintf("%+v", obj))
+			return nil
+		}),
+	)
+	testStruct := &foo{}
+	remaining, err := parser.ParseArgs(testStruct, []string{"+--level", "+2", "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a"}, remaining)
+	assert.Equal(t, &foo{Level: 2}, testStruct)
+	assert.Equal(t, []string{"&{Level:2}"}, logged)
+	_, err = parser.ParseArgs(&foo{}, []string{"--level", "x"})
+	assert.NotNil(t, err)
+	assert.Len(t, logged, 1)
+	failing := NewParser(WithAfterParse(func(ctx context.Context, obj interface{}) error {
+		return fmt.Errorf("invalid configuration")
+	}))
+	_, err = failing.ParseArgs(&foo{}, []string{})
+	assert.EqualError(t, err, "invalid configuration")
+	failing = NewParser(WithBeforeParse(func(ctx context.Context, obj interface{}, args []string) ([]string, error) {
+		return nil, fmt.Errorf("invalid arguments")
+	}))
+	_, err = failing.ParseArgs(&foo{}, []string{})
+	assert.EqualError(t, err, "invalid arguments")
+}
+
+func TestFieldCallbacks(t *testing.T) {
+	type get struct {
+		Key string `yagclif:"positional"`
+	}
+	type foo struct {
+		Level   int
+		Verbose bool
+		Names   []string `yagclif:"delimiter:,"`
+		Get     get      `yagclif:"command"`
+	}
+	var calls []string
+	record := func(ctx context.Context, raw string, value interface{}) error {
+		calls = append(calls, fmt.Sprintf("%s=%v", raw, value))
+		return nil
+	}
+	parser := NewParser(
+		WithFieldCallback("Level", record),
+		WithFieldCallback("verbose", record),
+		WithFieldCallback("Names", record),
+		WithFieldCallback("Key", record),
+	)
+	_, err := parser.ParseArgs(&foo{}, []string{"--level", "2", "--verbose", "--names", "a", "--names", "b", "get", "k"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2=2", "=true", "a=[a]", "b=[a b]", "k=k"}, calls)
+	failing := NewParser(WithFieldCallback("Level", func(ctx context.Context, raw string, value interface{}) error {
+		return fmt.Errorf("level %s refused", raw)
+	}))
+	_, err = failing.ParseArgs(&foo{}, []string{"--level", "3"})
+	assert.Contains(t, err.Error(), "level 3 refused")
+	calls = nil
+	ordered := NewParser(
+		WithFieldCallback("level", func(ctx context.Context, raw string, value interface{}) error {
+			calls = append(calls, "first")
+			return nil
+		}),
+		WithFieldCallback("Level", func(ctx context.Context, raw string, value interface{}) error {
+			calls = append(calls, "second")
+			return nil
+		}),
+	)
+	for i := 0; i < 20; i++ {
+		_, err = ordered.ParseArgs(&foo{}, []string{"--level", "4"})
+		assert.Nil(t, err)
+	}
+	for i := 0; i < len(calls); i += 2 {
+		assert.Equal(t, []string{"first", "second"}, calls[i:i+2])
+	}
+	unknown := NewParser(WithFieldCallback("Size", record))
+	_, err = unknown.ParseArgs(&foo{}, []string{})
+	assert.EqualError(t, err, "callback of unknown parameter Size")
+}
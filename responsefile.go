@@ -0,0 +1,46 @@
+package yagclif
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// If true the @file arguments are replaced
+// by the lines of the file.
+var ResponseFiles = false
+
+// Prefix of the arguments replaced by the lines of a file.
+const responseFilePrefix = "@"
+
+// Prefix of the lines of a response file that are skipped.
+const responseFileComment = "#"
+
+// Replaces the @file arguments by the lines of the files.
+// Empty lines and lines starting with # are skipped, the
+// arguments after -- and the lines of the files are not
+// expanded.
+func expandResponseFiles(args []string) ([]string, error) {
+	expanded := []string{}
+	for i, arg := range args {
+		if arg == flagsTerminator {
+			return append(expanded, args[i:]...), nil
+		}
+		if !strings.HasPrefix(arg, responseFilePrefix) || arg == responseFilePrefix {
+			expanded = append(expanded, arg)
+			continue
+		}
+		content, err := ioutil.ReadFile(strings.TrimPrefix(arg, responseFilePrefix))
+		if err != nil {
+			return nil, fmt.Errorf("response file %s : %s", arg, err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, responseFileComment) {
+				continue
+			}
+			expanded = append(expanded, line)
+		}
+	}
+	return expanded, nil
+}
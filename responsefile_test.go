@@ -0,0 +1,55 @@
+package yagclif
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yagclif")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "args.txt")
+	content := "# build flags\n--output\n  out dir  \r\n\n--verbose\n@other.txt\n"
+	assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0600))
+	t.Run("disabled", func(t *testing.T) {
+		type foo struct {
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		remaining, err := params.ParseArguments(&foo{}, []string{"@" + file})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"@" + file}, remaining)
+	})
+	ResponseFiles = true
+	defer func() { ResponseFiles = false }()
+	t.Run("works", func(t *testing.T) {
+		expanded, err := expandResponseFiles([]string{"a", "@" + file, "@", "--", "@" + file})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "--output", "out dir", "--verbose", "@other.txt", "@", "--", "@" + file}, expanded)
+	})
+	t.Run("missing file", func(t *testing.T) {
+		expanded, err := expandResponseFiles([]string{"@" + filepath.Join(dir, "missing.txt")})
+		assert.NotNil(t, err)
+		assert.Nil(t, expanded)
+	})
+	t.Run("parses", func(t *testing.T) {
+		type foo struct {
+			Output  string
+			Verbose bool
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"@" + file})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"@other.txt"}, remaining)
+		assert.Equal(t, &foo{Output: "out dir", Verbose: true}, testStruct)
+	})
+}
@@ -62,8 +62,8 @@ func getCustomCallBack(callBackFunctionValue reflect.Value, callBackCustomType r
 	if err != nil {
 		return nil, err
 	}
-	firstParamInstance := reflect.New(callBackCustomType)
 	return func(args []string) error {
+		firstParamInstance := reflect.New(callBackCustomType)
 		remainingArgs, err := params.ParseArguments(firstParamInstance.Interface(), args)
 		if err != nil {
 			return err
@@ -83,6 +83,17 @@ func TestGetCustomCallBack(t *testing.T) {
 		assert.Equal(t, &SomeStruct{
 			A: 1,
 		}, passedValue)
+		err = callback([]string{"--a", "2", "--b", "world"})
+		assert.Nil(t, err)
+		assert.Equal(t, &SomeStruct{
+			A: 2,
+			B: "world",
+		}, passedValue)
+		err = callback([]string{"--a", "3"})
+		assert.Nil(t, err)
+		assert.Equal(t, &SomeStruct{
+			A: 3,
+		}, passedValue)
 	})
 	t.Run("callBack formating error", func(t *testing.T) {
 		callbackFunc := reflect.ValueOf(func(i int, remainingArgs []string) {
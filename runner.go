@@ -0,0 +1,62 @@
+package yagclif
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// Runner is implemented by the structs and
+// commands executed by ParseAndRun.
+type Runner interface {
+	Run(ctx context.Context) error
+}
+
+// ParseAndRun fills the object like Parse then runs the deepest
+// selected command implementing Runner, or the object itself.
+func ParseAndRun(ctx context.Context, obj interface{}) error {
+	return NewParser().ParseAndRun(ctx, obj)
+}
+
+// ParseAndRun fills the object like ParseContext then runs the
+// deepest selected command implementing Runner, or the object itself.
+// The errors of Run go through the exit behavior like parse errors.
+func (parser *Parser) ParseAndRun(ctx context.Context, obj interface{}) error {
+	args, envArgs, err := withEnvArgs(os.Args[1:], parser.options.argsEnv)
+	if err != nil {
+		return parser.fail(err)
+	}
+	params, _, err := parser.parse(ctx, obj, args, envArgs)
+	if err != nil {
+		return err
+	}
+	runner := params.selectedRunner(obj)
+	if runner == nil {
+		return parser.fail(fmt.Errorf("%s does not implement Runner", reflect.TypeOf(obj).Elem()))
+	}
+	if err := runner.Run(ctx); err != nil {
+		return parser.fail(err)
+	}
+	return nil
+}
+
+// Returns the deepest runner among the
+// object and its selected commands.
+func (params *parameters) selectedRunner(obj interface{}) Runner {
+	for _, command := range params.commands() {
+		if !command.used {
+			continue
+		}
+		target := command.getValue(obj)
+		if target.Kind() != reflect.Ptr {
+			target = target.Addr()
+		}
+		if runner := command.commandParams.selectedRunner(target.Interface()); runner != nil {
+			return runner
+		}
+		break
+	}
+	runner, _ := obj.(Runner)
+	return runner
+}
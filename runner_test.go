@@ -0,0 +1,79 @@
+package yagclif
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type runnerGet struct {
+	Key string `yagclif:"positional"`
+	ran *string
+}
+
+func (get *runnerGet) Run(ctx context.Context) error {
+	*get.ran = "get " + get.Key
+	return nil
+}
+
+type runnerSet struct {
+	Key string `yagclif:"positional;mandatory"`
+}
+
+type runnerConfig struct {
+	Get  *runnerGet `yagclif:"command"`
+	Set  runnerSet  `yagclif:"command"`
+	Fail bool
+}
+
+func (config *runnerConfig) Run(ctx context.Context) error {
+	if config.Fail {
+		return fmt.Errorf("failed")
+	}
+	return ctx.Err()
+}
+
+func TestParseAndRun(t *testing.T) {
+	t.Run("runs the command", func(t *testing.T) {
+		os.Args = []string{"./main", "get", "key"}
+		ran := ""
+		config := &runnerConfig{Get: &runnerGet{ran: &ran}}
+		assert.Nil(t, ParseAndRun(context.Background(), config))
+		assert.Equal(t, "get key", ran)
+	})
+	t.Run("runs the object", func(t *testing.T) {
+		os.Args = []string{"./main", "--fail", "set", "key"}
+		err := ParseAndRun(context.Background(), &runnerConfig{})
+		assert.EqualError(t, err, "failed")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		os.Args = []string{"./main"}
+		err = ParseAndRun(ctx, &runnerConfig{})
+		assert.Equal(t, context.Canceled, err)
+	})
+	t.Run("return err", func(t *testing.T) {
+		os.Args = []string{"./main", "set"}
+		assert.NotNil(t, ParseAndRun(context.Background(), &runnerConfig{}))
+		os.Args = []string{"./main", "key"}
+		err := ParseAndRun(context.Background(), &runnerSet{})
+		assert.EqualError(t, err, "yagclif.runnerSet does not implement Runner")
+	})
+	t.Run("exits on run errors", func(t *testing.T) {
+		var output bytes.Buffer
+		codes := []int{}
+		parser := NewParser(WithOutput(&output), WithExitBehavior(func(code int) {
+			codes = append(codes, code)
+		}))
+		os.Args = []string{"./main", "--fail"}
+		assert.EqualError(t, parser.ParseAndRun(context.Background(), &runnerConfig{}), "failed")
+		os.Args = []string{"./main", "key"}
+		assert.NotNil(t, parser.ParseAndRun(context.Background(), &runnerSet{}))
+		assert.Equal(t, []int{ExitCode, ExitCode}, codes)
+		assert.Contains(t, output.String(), "failed")
+		assert.Contains(t, output.String(), "does not implement Runner")
+	})
+}
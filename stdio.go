@@ -0,0 +1,40 @@
+package yagclif
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// Stdio is the value of a path meaning the
+// standard input or the standard output.
+const Stdio = "-"
+
+// Writer whose Close method does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// OpenInput opens the file of the path for reading,
+// or returns the standard input when the path is -.
+// Closing the standard input returned does nothing.
+func OpenInput(path string) (io.ReadCloser, error) {
+	if path == Stdio {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// CreateOutput creates the file of the path for writing,
+// or returns the standard output when the path is -.
+// Closing the standard output returned does nothing.
+func CreateOutput(path string) (io.WriteCloser, error) {
+	if path == Stdio {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
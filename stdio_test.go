@@ -0,0 +1,56 @@
+package yagclif
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdio(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yagclif")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	t.Run("files", func(t *testing.T) {
+		output, err := CreateOutput(file)
+		assert.Nil(t, err)
+		_, err = output.Write([]byte("hello"))
+		assert.Nil(t, err)
+		assert.Nil(t, output.Close())
+		input, err := OpenInput(file)
+		assert.Nil(t, err)
+		content, err := ioutil.ReadAll(input)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", string(content))
+		assert.Nil(t, input.Close())
+		_, err = OpenInput(filepath.Join(dir, "missing"))
+		assert.NotNil(t, err)
+	})
+	t.Run("stdio", func(t *testing.T) {
+		input, err := OpenInput(Stdio)
+		assert.Nil(t, err)
+		assert.Nil(t, input.Close())
+		output, err := CreateOutput(Stdio)
+		assert.Nil(t, err)
+		assert.Nil(t, output.Close())
+		_, err = os.Stdout.Stat()
+		assert.Nil(t, err)
+	})
+	t.Run("parses", func(t *testing.T) {
+		type foo struct {
+			Input  string `yagclif:"file;implicit:-"`
+			Output string `yagclif:"positional"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		testStruct := &foo{}
+		remaining, err := params.ParseArguments(testStruct, []string{"--input", "-", "-", "-"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"-"}, remaining)
+		assert.Equal(t, &foo{Input: Stdio, Output: Stdio}, testStruct)
+	})
+}
@@ -0,0 +1,66 @@
+package yagclif
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Splits a command line into arguments like a shell does : arguments
+// are separated by white spaces, single quotes keep their content
+// as is, double quotes keep their content except backslashes
+// escaping double quotes and backslashes, and a backslash
+// outside of quotes keeps the next character as is.
+func tokenize(commandLine string) ([]string, error) {
+	args := []string{}
+	var current strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range commandLine {
+		switch {
+		case escaped:
+			// double quotes only escape double quotes and backslashes.
+			if quote == '"' && r != '"' && r != '\\' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' {
+				escaped = true
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			inArg, escaped = true, true
+		case r == '\'' || r == '"':
+			inArg, quote = true, r
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			inArg = true
+			current.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %s", quote, commandLine)
+	} else if escaped {
+		return nil, fmt.Errorf("trailing backslash in %s", commandLine)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,54 @@
+package yagclif
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Run("works", func(t *testing.T) {
+		commandLines := map[string][]string{
+			"":                             {},
+			"  serve   --port 8080 ":       {"serve", "--port", "8080"},
+			`--name "my app" 'it''s'`:      {"--name", "my app", "its"},
+			`"a \"b\" \\ \n" 'c \" d'`:     {`a "b" \ \n`, `c \" d`},
+			`a\ b \'c "" ''`:               {"a b", "'c", "", ""},
+			"tab\tseparated\nnew\r\nlines": {"tab", "separated", "new", "lines"},
+			`--path="C:\\x y"`:             {`--path=C:\x y`},
+		}
+		for commandLine, expected := range commandLines {
+			args, err := tokenize(commandLine)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, args, commandLine)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		for _, commandLine := range []string{`"a`, `'a`, `a\`} {
+			args, err := tokenize(commandLine)
+			assert.NotNil(t, err)
+			assert.Nil(t, args)
+		}
+	})
+}
+
+func TestParseString(t *testing.T) {
+	type foo struct {
+		Port int
+		Name string
+	}
+	t.Run("works", func(t *testing.T) {
+		testStruct := &foo{}
+		remaining, err := ParseString(testStruct, `serve --port 8080 --name "my app"`)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"serve"}, remaining)
+		assert.Equal(t, &foo{Port: 8080, Name: "my app"}, testStruct)
+	})
+	t.Run("return err", func(t *testing.T) {
+		for _, commandLine := range []string{`--name "my app`, "--port http"} {
+			remaining, err := ParseString(&foo{}, commandLine)
+			assert.NotNil(t, err)
+			assert.Nil(t, remaining)
+		}
+	})
+}
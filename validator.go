@@ -0,0 +1,59 @@
+package yagclif
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Validator checks the value of an argument before it is parsed.
+type Validator func(value string) error
+
+// ContextValidator is a Validator getting
+// the context of the parsing.
+type ContextValidator func(ctx context.Context, value string) error
+
+// Validators usable with the validator constraint by name.
+var validators = map[string]ContextValidator{}
+
+// RegisterValidator registers a validator usable by
+// the parameters with the validator constraint,
+// validators must be registered before parsing.
+func RegisterValidator(name string, validator Validator) error {
+	if validator == nil {
+		return fmt.Errorf("validator %s is nil", name)
+	}
+	return RegisterContextValidator(name, func(ctx context.Context, value string) error {
+		return validator(value)
+	})
+}
+
+// RegisterContextValidator registers a validator
+// like RegisterValidator that gets the context
+// of the parsing, to stop on its cancellation.
+func RegisterContextValidator(name string, validator ContextValidator) error {
+	if name == "" || strings.Contains(name, choicesDelimiter) {
+		return fmt.Errorf("invalid validator name %s", name)
+	}
+	if validator == nil {
+		return fmt.Errorf("validator %s is nil", name)
+	}
+	if validators[name] != nil {
+		return fmt.Errorf("validator %s already registered", name)
+	}
+	validators[name] = validator
+	return nil
+}
+
+// Wraps the setter to fail on values rejected
+// by one of the validators of the parameter.
+func (p *parameter) checkValidators(setter func(value string) error) func(value string) error {
+	return func(value string) error {
+		for _, name := range p.validators {
+			if err := validators[name](p.context(), value); err != nil {
+				return fmt.Errorf("parameter %s : %s", p.name, err)
+			}
+		}
+		return setter(value)
+	}
+}
@@ -0,0 +1,89 @@
+package yagclif
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterValidator(t *testing.T) {
+	defer func() { delete(validators, "port") }()
+	port := func(value string) error {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 || number > 65535 {
+			return fmt.Errorf("%s is not a port", value)
+		}
+		return nil
+	}
+	t.Run("works", func(t *testing.T) {
+		assert.Nil(t, RegisterValidator("port", port))
+	})
+	t.Run("errors", func(t *testing.T) {
+		assert.NotNil(t, RegisterValidator("port", port))
+		assert.NotNil(t, RegisterValidator("", port))
+		assert.NotNil(t, RegisterValidator("a|b", port))
+		assert.NotNil(t, RegisterValidator("nil", nil))
+	})
+	t.Run("validates", func(t *testing.T) {
+		type foo struct {
+			Port  string   `yagclif:"validator:port"`
+			Ports []string `yagclif:"validator:port;delimiter:,"`
+		}
+		fooVar := &foo{}
+		values := []string{"0", "80,http"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(foo{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(fooVar)
+			assert.Nil(t, err)
+			err = callBack(value)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "is not a port")
+		}
+		values = []string{"8080", "80,443"}
+		for i, value := range values {
+			param, err := newParameter(reflect.TypeOf(foo{}).Field(i))
+			assert.Nil(t, err)
+			callBack, err := param.SetterCallback(fooVar)
+			assert.Nil(t, err)
+			assert.Nil(t, callBack(value))
+		}
+		assert.Equal(t, &foo{Port: "8080", Ports: []string{"80", "443"}}, fooVar)
+	})
+	t.Run("gets the context", func(t *testing.T) {
+		defer delete(validators, "reachable")
+		reachable := func(ctx context.Context, value string) error {
+			return ctx.Err()
+		}
+		assert.Nil(t, RegisterContextValidator("reachable", reachable))
+		assert.NotNil(t, RegisterContextValidator("reachable", reachable))
+		assert.NotNil(t, RegisterContextValidator("nil", nil))
+		type foo struct {
+			Host string `yagclif:"validator:reachable"`
+		}
+		params, err := newParameters(reflect.TypeOf(foo{}))
+		assert.Nil(t, err)
+		_, _, err = params.parseSession(&foo{}, []string{"--host", "a"}, newParseSettings(context.Background(), false))
+		assert.Nil(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		param, err := newParameter(reflect.TypeOf(foo{}).Field(0))
+		assert.Nil(t, err)
+		param.settings = &parseSettings{ctx: ctx}
+		setter, err := param.SetterCallback(&foo{})
+		assert.Nil(t, err)
+		cancel()
+		assert.Contains(t, setter("a").Error(), "context canceled")
+	})
+	t.Run("unknown validator", func(t *testing.T) {
+		type foo struct {
+			Host string `yagclif:"validator:host"`
+		}
+		param, err := newParameter(reflect.TypeOf(foo{}).Field(0))
+		assert.NotNil(t, err)
+		assert.Nil(t, param)
+	})
+}